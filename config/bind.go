@@ -0,0 +1,447 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Struct tags read by Bind.
+const (
+	// TagName is the tag holding the name of a variable, or the prefix of the
+	// variables of a nested struct.
+	TagName = "mu"
+	// TagDefault is the tag holding the default value of a variable.
+	TagDefault = "default"
+	// TagDescription is the tag holding the description of a variable.
+	TagDescription = "desc"
+	// TagMinimum is the tag holding the minimum value of a variable, or the
+	// minimum length of a list or map.
+	TagMinimum = "min"
+	// TagMaximum is the tag holding the maximum value of a variable, or the
+	// maximum length of a list or map.
+	TagMaximum = "max"
+	// TagRequired is the tag that makes a variable required when set to
+	// "true".
+	TagRequired = "required"
+	// TagSecret is the tag that makes a string variable a secret when set to
+	// "true".
+	TagSecret = "secret"
+)
+
+// Types of struct fields bound by Bind.
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	stringsType   = reflect.TypeOf([]string(nil))
+	intsType      = reflect.TypeOf([]int(nil))
+	stringMapType = reflect.TypeOf(map[string]string(nil))
+)
+
+// Bind registers a variable for each tagged field of the struct pointed to by
+// target, and sets each field to the value of its variable. The tags of a
+// field are:
+//
+//   - mu: the name of the variable. Fields without a mu tag, and fields tagged
+//     mu:"-", are skipped.
+//   - default: the default value, in the same form as the environment. If
+//     absent, the default is the zero value.
+//   - desc: the description of the variable.
+//   - min and max: the minimum and maximum value of a number, duration, or byte
+//     size, or the minimum and maximum length of a list or map.
+//   - required:"true" makes the variable required (see WithRequired).
+//   - secret:"true" makes a string variable a secret (see WithSecret).
+//
+// The type of the variable follows the type of the field: int, string, bool,
+// time.Duration, float64, int64 (a byte size), []string, []int, and
+// map[string]string. A field of struct type is bound recursively; its mu tag,
+// if any, is a prefix joined to the names of its variables with "_", so that
+// a field tagged mu:"DB" holding a field tagged mu:"HOST" binds DB_HOST.
+//
+// The fields are set once, when Bind is called. They are not updated when
+// the configuration is reloaded; use the accessors of Config, or OnChange, to
+// observe reloaded values.
+func Bind(c Config, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: Bind target must be a non-nil pointer to a struct, got %T", status.ErrInvalidArgument, target)
+	}
+	return bindStruct(c, v.Elem(), "")
+}
+
+// bindStruct binds the fields of the struct v, prefixing the names of its
+// variables with prefix.
+func bindStruct(c Config, v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, tagged := field.Tag.Lookup(TagName)
+		if name == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			nested := prefix
+			if name != "" {
+				nested = prefix + name + "_"
+			}
+			if err := bindStruct(c, v.Field(i), nested); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged || name == "" {
+			continue
+		}
+		if err := bindField(c, v.Field(i), field, prefix+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindField registers the variable with the given name for field, and sets
+// v, the value of the field, to the value of the variable.
+func bindField(c Config, v reflect.Value, field reflect.StructField, name string) error {
+	tags, err := parseBindTags(field)
+	if err != nil {
+		return fmt.Errorf("%w: field %s: %s", status.ErrInvalidArgument, field.Name, err)
+	}
+	if tags.secret && field.Type.Kind() != reflect.String {
+		return fmt.Errorf("%w: field %s: only string variables can be secret", status.ErrInvalidArgument, field.Name)
+	}
+	var binder func(c Config, v reflect.Value, name string, tags bindTags) error
+	switch field.Type {
+	case durationType:
+		binder = bindDuration
+	case stringsType:
+		binder = bindStringList
+	case intsType:
+		binder = bindIntList
+	case stringMapType:
+		binder = bindStringMap
+	default:
+		switch field.Type.Kind() {
+		case reflect.Int:
+			binder = bindInt
+		case reflect.String:
+			binder = bindString
+		case reflect.Bool:
+			binder = bindBool
+		case reflect.Float64:
+			binder = bindFloat
+		case reflect.Int64:
+			binder = bindByteSize
+		default:
+			return fmt.Errorf("%w: field %s: unsupported type %s", status.ErrInvalidArgument, field.Name, field.Type)
+		}
+	}
+	if err := binder(c, v, name, tags); err != nil {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	return nil
+}
+
+// bindTags holds the tags of a bound field.
+type bindTags struct {
+	// def is the default value, or "" if absent.
+	def string
+	// desc is the description.
+	desc string
+	// min is the minimum, or "" if absent.
+	min string
+	// max is the maximum, or "" if absent.
+	max string
+	// required is true if the variable is required.
+	required bool
+	// secret is true if the variable is a secret.
+	secret bool
+}
+
+// parseBindTags parses the tags of a bound field.
+func parseBindTags(field reflect.StructField) (bindTags, error) {
+	tags := bindTags{
+		def:  field.Tag.Get(TagDefault),
+		desc: field.Tag.Get(TagDescription),
+		min:  field.Tag.Get(TagMinimum),
+		max:  field.Tag.Get(TagMaximum),
+	}
+	for tag, flag := range map[string]*bool{
+		TagRequired: &tags.required,
+		TagSecret:   &tags.secret,
+	} {
+		if s, ok := field.Tag.Lookup(tag); ok {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return tags, fmt.Errorf("invalid %s tag %q", tag, s)
+			}
+			*flag = b
+		}
+	}
+	return tags, nil
+}
+
+// variableOptions returns the options common to all variables for tags.
+func (t bindTags) variableOptions() []VariableOption {
+	if t.required {
+		return []VariableOption{WithRequired()}
+	}
+	return nil
+}
+
+// parseBound parses the text of a tag with parse, unless it is empty. It
+// returns whether the text was parsed.
+func parseBound[T any](text string, tag string, parse func(string) (T, error)) (T, bool, error) {
+	var v T
+	if text == "" {
+		return v, false, nil
+	}
+	v, err := parse(text)
+	if err != nil {
+		return v, false, fmt.Errorf("%w: invalid %s tag %q: %s", status.ErrInvalidArgument, tag, text, err)
+	}
+	return v, true, nil
+}
+
+// bindInt binds an int field.
+func bindInt(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, _, err := parseBound(tags.def, TagDefault, strconv.Atoi)
+	if err != nil {
+		return err
+	}
+	var opts []IntOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumValue(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumValue(max))
+	}
+	if err := c.NewInt(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetInt(int64(c.Int(name)))
+	return nil
+}
+
+// bindString binds a string field.
+func bindString(c Config, v reflect.Value, name string, tags bindTags) error {
+	if tags.min != "" || tags.max != "" {
+		return fmt.Errorf("%w: min and max tags are not supported for strings", status.ErrInvalidArgument)
+	}
+	var opts []StringOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if tags.secret {
+		opts = append(opts, WithSecret())
+	}
+	if err := c.NewString(name, tags.def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetString(c.String(name))
+	return nil
+}
+
+// bindBool binds a bool field.
+func bindBool(c Config, v reflect.Value, name string, tags bindTags) error {
+	if tags.min != "" || tags.max != "" {
+		return fmt.Errorf("%w: min and max tags are not supported for bools", status.ErrInvalidArgument)
+	}
+	def, _, err := parseBound(tags.def, TagDefault, strconv.ParseBool)
+	if err != nil {
+		return err
+	}
+	var opts []BoolOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if err := c.NewBool(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetBool(c.Bool(name))
+	return nil
+}
+
+// bindDuration binds a time.Duration field.
+func bindDuration(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, _, err := parseBound(tags.def, TagDefault, time.ParseDuration)
+	if err != nil {
+		return err
+	}
+	var opts []DurationOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, time.ParseDuration); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumDuration(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, time.ParseDuration); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumDuration(max))
+	}
+	if err := c.NewDuration(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetInt(int64(c.Duration(name)))
+	return nil
+}
+
+// bindFloat binds a float64 field.
+func bindFloat(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, _, err := parseBound(tags.def, TagDefault, parseFloat)
+	if err != nil {
+		return err
+	}
+	var opts []FloatOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, parseFloat); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumFloat(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, parseFloat); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumFloat(max))
+	}
+	if err := c.NewFloat(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetFloat(c.Float(name))
+	return nil
+}
+
+// bindByteSize binds an int64 field as a byte size.
+func bindByteSize(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, _, err := parseBound(tags.def, TagDefault, ParseByteSize)
+	if err != nil {
+		return err
+	}
+	var opts []ByteSizeOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, ParseByteSize); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumByteSize(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, ParseByteSize); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumByteSize(max))
+	}
+	if err := c.NewByteSize(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.SetInt(c.ByteSize(name))
+	return nil
+}
+
+// bindStringList binds a []string field. The default is separated by ",".
+func bindStringList(c Config, v reflect.Value, name string, tags bindTags) error {
+	var opts []StringListOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumStringListLength(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumStringListLength(max))
+	}
+	if err := c.NewStringList(name, SplitList(tags.def, ","), tags.desc, opts...); err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(c.StringList(name)))
+	return nil
+}
+
+// bindIntList binds an []int field. The default is separated by ",".
+func bindIntList(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, err := ParseIntList(tags.def, ",")
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s tag %q: %s", status.ErrInvalidArgument, TagDefault, tags.def, err)
+	}
+	var opts []IntListOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumIntListLength(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumIntListLength(max))
+	}
+	if err := c.NewIntList(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(c.IntList(name)))
+	return nil
+}
+
+// bindStringMap binds a map[string]string field. The default is separated by
+// "," and "=".
+func bindStringMap(c Config, v reflect.Value, name string, tags bindTags) error {
+	def, err := ParseStringMap(tags.def, ",", "=")
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s tag %q: %s", status.ErrInvalidArgument, TagDefault, tags.def, err)
+	}
+	var opts []StringMapOption
+	for _, o := range tags.variableOptions() {
+		opts = append(opts, o)
+	}
+	if min, ok, err := parseBound(tags.min, TagMinimum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMinimumStringMapLength(min))
+	}
+	if max, ok, err := parseBound(tags.max, TagMaximum, strconv.Atoi); err != nil {
+		return err
+	} else if ok {
+		opts = append(opts, WithMaximumStringMapLength(max))
+	}
+	if err := c.NewStringMap(name, def, tags.desc, opts...); err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(c.StringMap(name)))
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// testBindDatabase is a nested struct bound by Test_Bind.
+type testBindDatabase struct {
+	Host     string `mu:"HOST" default:"localhost" desc:"Database host."`
+	Password string `mu:"PASSWORD" desc:"Database password." secret:"true"`
+	MaxConns int    `mu:"MAX_CONNS" default:"10" min:"1" max:"100"`
+}
+
+// testBindConfig is the struct bound by Test_Bind.
+type testBindConfig struct {
+	Message  string            `mu:"MESSAGE" default:"Hello, World!" desc:"The message to display."`
+	DevMode  bool              `mu:"DEV_MODE" desc:"Enable development mode."`
+	Port     int               `mu:"PORT" default:"8080" min:"1" max:"65535"`
+	Timeout  time.Duration     `mu:"TIMEOUT" default:"5s" max:"1m"`
+	Ratio    float64           `mu:"RATIO" default:"0.5" min:"0" max:"1"`
+	MaxBody  int64             `mu:"MAX_BODY" default:"1Mi"`
+	Hosts    []string          `mu:"HOSTS" default:"a,b"`
+	Ports    []int             `mu:"PORTS" default:"1,2" max:"4"`
+	Labels   map[string]string `mu:"LABELS" default:"team=core"`
+	Database testBindDatabase  `mu:"DB"`
+	Ignored  string
+	Skipped  string `mu:"-"`
+}
+
+// Test_Bind tests binding a struct to defaults and source values.
+func Test_Bind(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("PORT", "9090")
+	src.SetValue("DEV_MODE", "true")
+	src.SetValue("DB_HOST", "db.example.com")
+	src.SetValue("DB_PASSWORD", "hunter2")
+	c := New(WithSource(src))
+	var cfg testBindConfig
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Bind() = %v; want nil", err)
+	}
+	want := testBindConfig{
+		Message: "Hello, World!",
+		DevMode: true,
+		Port:    9090,
+		Timeout: 5 * time.Second,
+		Ratio:   0.5,
+		MaxBody: 1 << 20,
+		Hosts:   []string{"a", "b"},
+		Ports:   []int{1, 2},
+		Labels:  map[string]string{"team": "core"},
+		Database: testBindDatabase{
+			Host:     "db.example.com",
+			Password: "hunter2",
+			MaxConns: 10,
+		},
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("Bind() mismatch (-want +got):\n%s", diff)
+	}
+	var names []string
+	for _, v := range c.Variables() {
+		names = append(names, v.Name)
+		if v.Name == "DB_PASSWORD" && !v.Secret {
+			t.Errorf("DB_PASSWORD is not a secret")
+		}
+		if v.Name == "PORT" && (v.Minimum != "1" || v.Maximum != "65535") {
+			t.Errorf("PORT range = %s..%s; want 1..65535", v.Minimum, v.Maximum)
+		}
+	}
+	wantNames := []string{"MESSAGE", "DEV_MODE", "PORT", "TIMEOUT", "RATIO", "MAX_BODY", "HOSTS", "PORTS", "LABELS", "DB_HOST", "DB_PASSWORD", "DB_MAX_CONNS"}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("Variables() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Test_Bind_Validation tests that Bind reuses the validation of the
+// variables.
+func Test_Bind_Validation(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("DB_MAX_CONNS", "1000")
+	var cfg testBindConfig
+	if err := Bind(New(WithSource(src)), &cfg); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("Bind() = %v; want %v", err, status.ErrOutOfRange)
+	}
+}
+
+// Test_Bind_Required tests the required tag.
+func Test_Bind_Required(t *testing.T) {
+	t.Parallel()
+	var cfg struct {
+		URL string `mu:"URL" required:"true"`
+	}
+	if err := Bind(New(WithSource(newTestSource())), &cfg); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Bind() = %v; want %v", err, status.ErrNotFound)
+	}
+}
+
+// Test_Bind_Invalid tests that invalid targets and tags are errors.
+func Test_Bind_Invalid(t *testing.T) {
+	t.Parallel()
+	var notStruct int
+	var badDefault struct {
+		Port int `mu:"PORT" default:"eighty"`
+	}
+	var badMinimum struct {
+		Timeout time.Duration `mu:"TIMEOUT" min:"soon"`
+	}
+	var badType struct {
+		Channel chan int `mu:"CHANNEL"`
+	}
+	var secretInt struct {
+		Port int `mu:"PORT" secret:"true"`
+	}
+	var stringRange struct {
+		Name string `mu:"NAME" min:"1"`
+	}
+	var badRequired struct {
+		Name string `mu:"NAME" required:"yes please"`
+	}
+	for name, target := range map[string]interface{}{
+		"nil":          nil,
+		"non-pointer":  badDefault,
+		"not-struct":   &notStruct,
+		"bad-default":  &badDefault,
+		"bad-minimum":  &badMinimum,
+		"bad-type":     &badType,
+		"secret-int":   &secretInt,
+		"string-range": &stringRange,
+		"bad-required": &badRequired,
+	} {
+		if err := Bind(New(WithSource(newTestSource())), target); !errors.Is(err, status.ErrInvalidArgument) {
+			t.Errorf("%s: Bind() = %v; want %v", name, err, status.ErrInvalidArgument)
+		}
+	}
+}
+
+// Test_Bind_AlreadyExists tests that binding a variable twice is an error.
+func Test_Bind_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	var cfg struct {
+		Message string `mu:"MESSAGE"`
+	}
+	c := New(WithSource(newTestSource()))
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Bind() = %v; want nil", err)
+	}
+	if err := Bind(c, &cfg); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("Bind() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
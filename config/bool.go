@@ -16,7 +16,7 @@ package config
 
 import (
 	"errors"
-	"fmt"
+	"strconv"
 
 	"github.com/neuralnorthwest/mu/bug"
 	"github.com/neuralnorthwest/mu/status"
@@ -32,18 +32,22 @@ type Bool struct {
 	defaultValue bool
 	// description is the description of the variable.
 	description string
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// BoolOption is an option for a bool variable.
+type BoolOption interface {
+	// applyBool applies the option to a bool variable.
+	applyBool(c *configImpl, b *Bool) error
 }
 
 // NewBool creates a new bool variable.
-func (c *configImpl) NewBool(name string, defaultValue bool, description string) error {
-	if _, ok := c.ints[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.strings[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.bools[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
+func (c *configImpl) NewBool(name string, defaultValue bool, description string, options ...BoolOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
 	}
 	b := &Bool{
 		name:         name,
@@ -51,20 +55,69 @@ func (c *configImpl) NewBool(name string, defaultValue bool, description string)
 		defaultValue: defaultValue,
 		description:  description,
 	}
-	v, err := c.source.LoadBool(name)
-	if err == nil {
-		b.value = v
+	for _, opt := range options {
+		if err := opt.applyBool(c, b); err != nil {
+			return err
+		}
 	}
-	if err != nil && !errors.Is(err, status.ErrNotFound) {
-		return err
+	return c.register(name, b)
+}
+
+// load implements variable.
+func (b *Bool) load(src Source) (interface{}, error) {
+	v, err := src.LoadBool(b.name)
+	if errors.Is(err, status.ErrNotFound) {
+		if b.required {
+			return nil, errRequired
+		}
+		return b.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (b *Bool) set(value interface{}) bool {
+	old := b.value
+	b.value = value.(bool)
+	return b.value != old
+}
+
+// format implements variable.
+func (b *Bool) format() string {
+	return b.text(b.value)
+}
+
+// text implements variable.
+func (b *Bool) text(value interface{}) string {
+	return strconv.FormatBool(value.(bool))
+}
+
+// initialValue implements variable.
+func (b *Bool) initialValue() interface{} {
+	return b.defaultValue
+}
+
+// info implements variable.
+func (b *Bool) info() VariableInfo {
+	return VariableInfo{
+		Name:         b.name,
+		Type:         TypeBool,
+		Description:  b.description,
+		Default:      strconv.FormatBool(b.defaultValue),
+		Value:        b.format(),
+		Required:     b.required,
+		defaultValue: b.defaultValue,
 	}
-	c.bools[name] = b
-	return nil
 }
 
 // Bool returns the value of the bool variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, Bool returns false.
 func (c *configImpl) Bool(name string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if b, ok := c.bools[name]; ok {
 		return b.value
 	}
@@ -75,6 +128,8 @@ func (c *configImpl) Bool(name string) bool {
 // DescribeBool returns the description of the bool variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, DescribeBool returns "".
 func (c *configImpl) DescribeBool(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if b, ok := c.bools[name]; ok {
 		return b.description
 	}
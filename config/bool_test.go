@@ -90,7 +90,7 @@ func Test_NewBool(t *testing.T) {
 				return
 			}
 			opts := []cmp.Option{
-				cmp.AllowUnexported(Bool{}),
+				cmp.AllowUnexported(Bool{}, legacyNames{}),
 			}
 			if diff := cmp.Diff(tc.expected, b, opts...); diff != "" {
 				t.Errorf("unexpected variable (-want +got):\n%s", diff)
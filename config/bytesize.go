@@ -0,0 +1,218 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// ByteSize is a configuration value that is a size in bytes.
+type ByteSize struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value int64
+	// defaultValue is the default value of the variable.
+	defaultValue int64
+	// description is the description of the variable.
+	description string
+	// minimumValue is the minimum value of the variable.
+	minimumValue int64
+	// maximumValue is the maximum value of the variable.
+	maximumValue int64
+	// clampValue is true if the value should be clamped to the minimum and maximum values.
+	clampValue bool
+	// validator is the validator for the variable.
+	validator func(int64) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// ByteSizeOption is an option for a byte size variable.
+type ByteSizeOption interface {
+	// applyByteSize applies the option to a byte size variable.
+	applyByteSize(c *configImpl, b *ByteSize) error
+}
+
+// byteSizeOption is a ByteSizeOption implemented by a function.
+type byteSizeOption func(*configImpl, *ByteSize) error
+
+// applyByteSize implements ByteSizeOption.
+func (o byteSizeOption) applyByteSize(c *configImpl, b *ByteSize) error {
+	return o(c, b)
+}
+
+// WithMinimumByteSize returns an option that sets the minimum value for a byte size variable.
+func WithMinimumByteSize(min int64) ByteSizeOption {
+	return byteSizeOption(func(c *configImpl, b *ByteSize) error {
+		b.minimumValue = min
+		return nil
+	})
+}
+
+// WithMaximumByteSize returns an option that sets the maximum value for a byte size variable.
+func WithMaximumByteSize(max int64) ByteSizeOption {
+	return byteSizeOption(func(c *configImpl, b *ByteSize) error {
+		b.maximumValue = max
+		return nil
+	})
+}
+
+// WithByteSizeValidator returns an option that sets the validator for a byte size variable.
+func WithByteSizeValidator(f func(int64) error) ByteSizeOption {
+	return byteSizeOption(func(c *configImpl, b *ByteSize) error {
+		b.validator = f
+		return nil
+	})
+}
+
+// WithByteSizeClamping returns an option that enables clamping for a byte size variable.
+func WithByteSizeClamping() ByteSizeOption {
+	return byteSizeOption(func(c *configImpl, b *ByteSize) error {
+		b.clampValue = true
+		return nil
+	})
+}
+
+// NewByteSize creates a new byte size variable.
+func (c *configImpl) NewByteSize(name string, defaultValue int64, description string, options ...ByteSizeOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	b := &ByteSize{
+		name:         name,
+		defaultValue: defaultValue,
+		description:  description,
+		minimumValue: 0,
+		maximumValue: math.MaxInt64,
+	}
+	for _, opt := range options {
+		if err := opt.applyByteSize(c, b); err != nil {
+			return err
+		}
+	}
+	if b.minimumValue > b.maximumValue {
+		return status.ErrInvalidRange
+	}
+	var err error
+	b.defaultValue, err = clampOrError(b.defaultValue, b.minimumValue, b.maximumValue, b.clampValue)
+	if err != nil {
+		return err
+	}
+	if b.validator != nil {
+		if err := b.validator(b.defaultValue); err != nil {
+			return err
+		}
+	}
+	return c.register(name, b)
+}
+
+// load implements variable.
+func (b *ByteSize) load(src Source) (interface{}, error) {
+	v, err := src.LoadByteSize(b.name)
+	if errors.Is(err, status.ErrNotFound) {
+		if b.required {
+			return nil, errRequired
+		}
+		return b.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v, err = clampOrError(v, b.minimumValue, b.maximumValue, b.clampValue)
+	if err != nil {
+		return nil, err
+	}
+	if b.validator != nil {
+		if err := b.validator(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (b *ByteSize) set(value interface{}) bool {
+	old := b.value
+	b.value = value.(int64)
+	return b.value != old
+}
+
+// format implements variable.
+func (b *ByteSize) format() string {
+	return b.text(b.value)
+}
+
+// text implements variable.
+func (b *ByteSize) text(value interface{}) string {
+	return strconv.FormatInt(value.(int64), 10)
+}
+
+// initialValue implements variable.
+func (b *ByteSize) initialValue() interface{} {
+	return b.defaultValue
+}
+
+// info implements variable.
+func (b *ByteSize) info() VariableInfo {
+	info := VariableInfo{
+		Name:         b.name,
+		Type:         TypeByteSize,
+		Description:  b.description,
+		Default:      strconv.FormatInt(b.defaultValue, 10),
+		Value:        b.format(),
+		Required:     b.required,
+		Clamped:      b.clampValue,
+		defaultValue: b.defaultValue,
+	}
+	if b.minimumValue != 0 {
+		info.Minimum = strconv.FormatInt(b.minimumValue, 10)
+	}
+	if b.maximumValue != math.MaxInt64 {
+		info.Maximum = strconv.FormatInt(b.maximumValue, 10)
+	}
+	return info
+}
+
+// ByteSize returns the value of the byte size variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, ByteSize returns 0.
+func (c *configImpl) ByteSize(name string) int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if b, ok := c.byteSizes[name]; ok {
+		return b.value
+	}
+	defer bug.Bugf("config: byte size variable %q does not exist", name)
+	return 0
+}
+
+// DescribeByteSize returns the description of the byte size variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, DescribeByteSize returns "".
+func (c *configImpl) DescribeByteSize(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if b, ok := c.byteSizes[name]; ok {
+		return b.description
+	}
+	defer bug.Bugf("config: byte size variable %q does not exist", name)
+	return ""
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_NewByteSize_Case is a test case for NewByteSize.
+type Test_NewByteSize_Case struct {
+	// name is the name of the test case.
+	name string
+	// defaultValue is the default value of the variable.
+	defaultValue int64
+	// options are the options for the variable.
+	options []ByteSizeOption
+	// expected is the expected result.
+	expected *ByteSize
+	// err is the expected error.
+	err error
+}
+
+// Test_NewByteSize tests the NewByteSize function.
+func Test_NewByteSize(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_NewByteSize_Case{
+		{
+			name:         "basic case",
+			defaultValue: 1024,
+			expected: &ByteSize{
+				name:         "test",
+				value:        1024,
+				defaultValue: 1024,
+				description:  "test",
+				minimumValue: 0,
+				maximumValue: math.MaxInt64,
+			},
+		},
+		{
+			name:         "with minimum value, default value less than minimum value",
+			defaultValue: 1024,
+			options:      []ByteSizeOption{WithMinimumByteSize(2048)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with minimum value, default value less than minimum value, with clamping",
+			defaultValue: 1024,
+			options:      []ByteSizeOption{WithMinimumByteSize(2048), WithByteSizeClamping()},
+			expected: &ByteSize{
+				name:         "test",
+				value:        2048,
+				defaultValue: 2048,
+				description:  "test",
+				minimumValue: 2048,
+				maximumValue: math.MaxInt64,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value",
+			defaultValue: 3072,
+			options:      []ByteSizeOption{WithMaximumByteSize(2048)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value, with clamping",
+			defaultValue: 3072,
+			options:      []ByteSizeOption{WithMaximumByteSize(2048), WithByteSizeClamping()},
+			expected: &ByteSize{
+				name:         "test",
+				value:        2048,
+				defaultValue: 2048,
+				description:  "test",
+				minimumValue: 0,
+				maximumValue: 2048,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with validator, default value invalid",
+			defaultValue: 1024,
+			options: []ByteSizeOption{WithByteSizeValidator(func(v int64) error {
+				return status.ErrInvalidArgument
+			})},
+			err: status.ErrInvalidArgument,
+		},
+		{
+			name:         "minimum value greater than maximum value",
+			defaultValue: 2048,
+			options:      []ByteSizeOption{WithMinimumByteSize(3072), WithMaximumByteSize(1024)},
+			err:          status.ErrInvalidRange,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(WithSource(newNullSource())).(*configImpl)
+			err := c.NewByteSize("test", tc.defaultValue, "test", tc.options...)
+			if err != nil {
+				if tc.err == nil {
+					t.Errorf("NewByteSize() = %v; want nil", err)
+				} else if !errors.Is(err, tc.err) {
+					t.Errorf("NewByteSize() = %v; want %v", err, tc.err)
+				}
+				return
+			}
+			if tc.err != nil {
+				t.Errorf("NewByteSize() = nil; want %v", tc.err)
+				return
+			}
+			opts := []cmp.Option{
+				cmp.AllowUnexported(ByteSize{}, legacyNames{}),
+				cmpopts.IgnoreFields(ByteSize{}, "validator"),
+			}
+			if diff := cmp.Diff(tc.expected, c.byteSizes["test"], opts...); diff != "" {
+				t.Errorf("NewByteSize() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_NewByteSize_AlreadyExists tests that NewByteSize() returns an error if the
+// variable name already exists.
+func Test_NewByteSize_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewByteSize("test", 1024, "test"); err != nil {
+		t.Fatalf("NewByteSize() = %v; want nil", err)
+	}
+	if err := c.NewByteSize("test", 1024, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewByteSize() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_ByteSize_FromTestSource tests that ByteSize() returns the value set in the test
+// source, and that the value is range checked, clamped, and validated.
+func Test_ByteSize_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", "2Ki")
+	src.SetValue("clamped", "3Ki")
+	src.SetValue("invalid", "-1")
+	src.SetValue("malformed", "12XB")
+	c := New(WithSource(src))
+	if err := c.NewByteSize("test", 1024, "test"); err != nil {
+		t.Fatalf("NewByteSize() = %v; want nil", err)
+	}
+	if v := c.ByteSize("test"); v != 2048 {
+		t.Errorf("ByteSize() = %v; want %v", v, 2048)
+	}
+	if err := c.NewByteSize("clamped", 1024, "test", WithMaximumByteSize(2048)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewByteSize() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewByteSize("clamped", 1024, "test", WithMaximumByteSize(2048), WithByteSizeClamping()); err != nil {
+		t.Fatalf("NewByteSize() = %v; want nil", err)
+	}
+	if v := c.ByteSize("clamped"); v != 2048 {
+		t.Errorf("ByteSize() = %v; want %v", v, 2048)
+	}
+	validator := WithByteSizeValidator(func(v int64) error {
+		if v < 0 {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})
+	if err := c.NewByteSize("invalid", 1024, "test", WithMinimumByteSize(-1*2), validator); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewByteSize() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewByteSize("malformed", 1024, "test"); err == nil {
+		t.Errorf("NewByteSize() = nil; want error")
+	}
+}
+
+// Test_ByteSize_FromErrorSource tests that NewByteSize() returns the error returned by
+// the source.
+func Test_ByteSize_FromErrorSource(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newErrorSource(status.ErrInvalidArgument)))
+	if err := c.NewByteSize("test", 1024, "test"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewByteSize() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_ByteSize_FromEnv tests that ByteSize() returns the value set in the environment.
+func Test_ByteSize_FromEnv(t *testing.T) {
+	t.Setenv("prefix-test", "2Ki")
+	c := New(WithLoadPrefix("prefix-"))
+	if err := c.NewByteSize("test", 1024, "test"); err != nil {
+		t.Fatalf("NewByteSize() = %v; want nil", err)
+	}
+	if v := c.ByteSize("test"); v != 2048 {
+		t.Errorf("ByteSize() = %v; want %v", v, 2048)
+	}
+}
+
+// Test_ByteSize_Unknown tests that ByteSize() and DescribeByteSize() call bug.Bug if the
+// variable name is unknown.
+func Test_ByteSize_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: byte size variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.ByteSize("test")
+	_ = c.DescribeByteSize("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeByteSize tests that DescribeByteSize() returns the correct value.
+func Test_DescribeByteSize(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewByteSize("test", 1024, "description"); err != nil {
+		t.Fatalf("NewByteSize() = %v; want nil", err)
+	}
+	if v := c.DescribeByteSize("test"); v != "description" {
+		t.Errorf("DescribeByteSize() = %v; want description", v)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Layer is a named Source in a chain of sources created by NewChainSource.
+type Layer struct {
+	// Name is the name of the layer. It is used to report which layer
+	// supplied the value of a variable.
+	Name string
+	// Source is the source of the layer.
+	Source Source
+}
+
+// chainSource is a source that queries an ordered list of layers.
+type chainSource struct {
+	// layers are the layers, in order of decreasing precedence.
+	layers []Layer
+	// origins, if not nil, records the name of the layer that supplied each
+	// value loaded, keyed by the name it was loaded with.
+	origins map[string]string
+}
+
+var _ Source = (*chainSource)(nil)
+var _ Reloader = (*chainSource)(nil)
+var _ Stager = (*chainSource)(nil)
+var _ Watcher = (*chainSource)(nil)
+var _ originTracker = (*chainSource)(nil)
+
+// originTracker is implemented by sources that can report where the values
+// they load come from.
+type originTracker interface {
+	// trackOrigins returns a view of the source that records in origins the
+	// name of the layer that supplied each value it loads, keyed by the name
+	// the value was loaded with.
+	trackOrigins(origins map[string]string) Source
+}
+
+// NewChainSource creates a Source from the given layers. Layers are given
+// in order of decreasing precedence: each variable is loaded from the first
+// layer that does not return status.ErrNotFound. For example, to give
+// command-line flags precedence over the environment, and the environment
+// precedence over a file:
+//
+//	config.NewChainSource(
+//		config.Layer{Name: "flags", Source: flagSource},
+//		config.Layer{Name: "env", Source: config.NewEnvSource()},
+//		config.Layer{Name: "file", Source: fileSource},
+//	)
+//
+// If every layer returns status.ErrNotFound, the variable keeps its default
+// value. If a layer returns any other error, the error is returned and lower
+// layers are not queried. Config.Variables reports the name of the layer that
+// supplied the value of each variable.
+func NewChainSource(layers ...Layer) Source {
+	return &chainSource{layers: layers}
+}
+
+// SetPrefix sets the prefix of every layer.
+func (s *chainSource) SetPrefix(prefix string) {
+	for _, l := range s.layers {
+		l.Source.SetPrefix(prefix)
+	}
+}
+
+// LoadInt loads the value of the int variable with the given name.
+func (s *chainSource) LoadInt(name string) (int, error) {
+	var v int
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadInt(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadString loads the value of the string variable with the given name.
+func (s *chainSource) LoadString(name string) (string, error) {
+	var v string
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadString(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadBool loads the value of the bool variable with the given name.
+func (s *chainSource) LoadBool(name string) (bool, error) {
+	var v bool
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadBool(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadDuration loads the value of the duration variable with the given name.
+func (s *chainSource) LoadDuration(name string) (time.Duration, error) {
+	var v time.Duration
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadDuration(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (s *chainSource) LoadFloat(name string) (float64, error) {
+	var v float64
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadFloat(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+func (s *chainSource) LoadByteSize(name string) (int64, error) {
+	var v int64
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadByteSize(name)
+		return err
+	})
+	return v, err
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name.
+func (s *chainSource) LoadStringList(name string, sep string) ([]string, error) {
+	var v []string
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadStringList(name, sep)
+		return err
+	})
+	return v, err
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+func (s *chainSource) LoadIntList(name string, sep string) ([]int, error) {
+	var v []int
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadIntList(name, sep)
+		return err
+	})
+	return v, err
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name.
+func (s *chainSource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	var v map[string]string
+	err := s.load(name, func(src Source) (err error) {
+		v, err = src.LoadStringMap(name, sep, kvSep)
+		return err
+	})
+	return v, err
+}
+
+// Reload reloads every layer that implements Reloader. It returns the first
+// error encountered.
+func (s *chainSource) Reload() error {
+	for _, l := range s.layers {
+		if r, ok := l.Source.(Reloader); ok {
+			if err := r.Reload(); err != nil {
+				return fmt.Errorf("%s: %w", l.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Stage stages every layer that implements Stager, and reloads every other
+// layer that implements Reloader. It returns a chain of the staged layers,
+// and a function that applies the staged values to the layers.
+func (s *chainSource) Stage() (Source, func(), error) {
+	layers := make([]Layer, len(s.layers))
+	var commits []func()
+	for i, l := range s.layers {
+		src, commit, err := stage(l.Source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", l.Name, err)
+		}
+		layers[i] = Layer{Name: l.Name, Source: src}
+		commits = append(commits, commit)
+	}
+	return &chainSource{layers: layers}, func() {
+		for _, commit := range commits {
+			commit()
+		}
+	}, nil
+}
+
+// Watch watches every layer that implements Watcher, calling notify when any
+// of them reports a change. It blocks until ctx is canceled. If no layer
+// implements Watcher, Watch returns status.ErrNotImplemented.
+func (s *chainSource) Watch(ctx context.Context, notify func()) error {
+	var watches []func() error
+	for _, l := range s.layers {
+		if w, ok := l.Source.(Watcher); ok {
+			w := w
+			watches = append(watches, func() error {
+				return w.Watch(ctx, notify)
+			})
+		}
+	}
+	if len(watches) == 0 {
+		return status.ErrNotImplemented
+	}
+	return watchAll(watches)
+}
+
+// trackOrigins returns a view of the chain that records in origins the name
+// of the layer that supplied each value it loads.
+func (s *chainSource) trackOrigins(origins map[string]string) Source {
+	return &chainSource{layers: s.layers, origins: origins}
+}
+
+// load calls load for each layer in turn until one of them does not return
+// status.ErrNotFound, and records the name of that layer if the chain tracks
+// origins.
+func (s *chainSource) load(name string, load func(src Source) error) error {
+	for _, l := range s.layers {
+		err := load(l.Source)
+		if errors.Is(err, status.ErrNotFound) {
+			continue
+		}
+		if err == nil && s.origins != nil {
+			s.origins[name] = l.Name
+		}
+		return err
+	}
+	return status.ErrNotFound
+}
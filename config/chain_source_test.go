@@ -0,0 +1,218 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// newLayeredTestSources returns a chain of three test sources, in order of
+// decreasing precedence.
+func newLayeredTestSources() (Source, *testSource, *testSource, *testSource) {
+	flags := newTestSource()
+	env := newTestSource()
+	file := newTestSource()
+	chain := NewChainSource(
+		Layer{Name: "flags", Source: flags},
+		Layer{Name: "env", Source: env},
+		Layer{Name: "file", Source: file},
+	)
+	return chain, flags, env, file
+}
+
+// Test_ChainSource_Precedence tests that the first layer with a value wins.
+func Test_ChainSource_Precedence(t *testing.T) {
+	t.Parallel()
+	chain, flags, env, file := newLayeredTestSources()
+	file.SetValue("PORT", "1")
+	file.SetValue("MESSAGE", "file")
+	file.SetValue("DEBUG", "false")
+	env.SetValue("PORT", "2")
+	env.SetValue("MESSAGE", "env")
+	flags.SetValue("PORT", "3")
+	c := New(WithSource(chain))
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewString("MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewBool("DEBUG", true, "debug"); err != nil {
+		t.Fatalf("NewBool() = %v; want nil", err)
+	}
+	if err := c.NewString("MISSING", "default", "missing"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 3 {
+		t.Errorf("Int() = %v; want 3", v)
+	}
+	if v := c.String("MESSAGE"); v != "env" {
+		t.Errorf("String() = %v; want env", v)
+	}
+	if v := c.Bool("DEBUG"); v {
+		t.Errorf("Bool() = %v; want false", v)
+	}
+	if v := c.String("MISSING"); v != "default" {
+		t.Errorf("String() = %v; want default", v)
+	}
+	for name, layer := range map[string]string{
+		"PORT":    "flags",
+		"MESSAGE": "env",
+		"DEBUG":   "file",
+		"MISSING": "",
+	} {
+		if v := variableOrigin(c, name); v != layer {
+			t.Errorf("Origin(%q) = %q; want %q", name, v, layer)
+		}
+	}
+}
+
+// Test_ChainSource_Error tests that an error other than status.ErrNotFound
+// stops the search.
+func Test_ChainSource_Error(t *testing.T) {
+	t.Parallel()
+	file := newTestSource()
+	file.SetValue("PORT", "1")
+	chain := NewChainSource(
+		Layer{Name: "broken", Source: newErrorSource(status.ErrInvalidArgument)},
+		Layer{Name: "file", Source: file},
+	)
+	if _, err := chain.LoadInt("PORT"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadInt() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	c := New(WithSource(chain), WithCollectErrors())
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := variableOrigin(c, "PORT"); v != "" {
+		t.Errorf("Origin = %q; want \"\"", v)
+	}
+}
+
+// Test_ChainSource_NotFound tests that an empty chain, or a chain of sources
+// without values, returns status.ErrNotFound.
+func Test_ChainSource_NotFound(t *testing.T) {
+	t.Parallel()
+	for _, chain := range []Source{
+		NewChainSource(),
+		NewChainSource(Layer{Name: "null", Source: newNullSource()}),
+	} {
+		if _, err := chain.LoadInt("X"); !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("LoadInt() = %v; want %v", err, status.ErrNotFound)
+		}
+		if _, err := chain.LoadString("X"); !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("LoadString() = %v; want %v", err, status.ErrNotFound)
+		}
+		if _, err := chain.LoadBool("X"); !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("LoadBool() = %v; want %v", err, status.ErrNotFound)
+		}
+	}
+}
+
+// Test_ChainSource_LoadPrefix tests that the load prefix is passed to every
+// layer.
+func Test_ChainSource_LoadPrefix(t *testing.T) {
+	t.Parallel()
+	chain, _, env, file := newLayeredTestSources()
+	env.SetValue("APP_MESSAGE", "env")
+	file.SetValue("APP_PORT", "1")
+	c := New(WithSource(chain), WithLoadPrefix("APP_"))
+	if err := c.NewString("MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := c.String("MESSAGE"); v != "env" {
+		t.Errorf("String() = %v; want env", v)
+	}
+	if v := c.Int("PORT"); v != 1 {
+		t.Errorf("Int() = %v; want 1", v)
+	}
+}
+
+// Test_ChainSource_Env tests layering the environment over a test source.
+func Test_ChainSource_Env(t *testing.T) {
+	t.Setenv("MESSAGE", "env")
+	file := newTestSource()
+	file.SetValue("MESSAGE", "file")
+	chain := NewChainSource(
+		Layer{Name: "env", Source: NewEnvSource()},
+		Layer{Name: "file", Source: file},
+	)
+	if v, err := chain.LoadString("MESSAGE"); err != nil || v != "env" {
+		t.Errorf("LoadString() = %v, %v; want env, nil", v, err)
+	}
+	c := New(WithSource(chain))
+	if err := c.NewString("MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := variableOrigin(c, "MESSAGE"); v != "env" {
+		t.Errorf("Origin = %q; want env", v)
+	}
+}
+
+// Test_ChainSource_Origin_RejectedReload tests that a rejected reload does
+// not change the reported origins.
+func Test_ChainSource_Origin_RejectedReload(t *testing.T) {
+	t.Parallel()
+	chain, flags, env, file := newLayeredTestSources()
+	file.SetValue("PORT", "1")
+	c := New(WithSource(chain))
+	if err := c.NewInt("PORT", 1, "port", WithMinimumValue(1)); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	c.Validate(func(c Config) error {
+		if c.Int("PORT") == 3 {
+			return Violation("port 3 is reserved", "PORT")
+		}
+		return nil
+	})
+	env.SetValue("PORT", "0")
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() = nil; want error")
+	}
+	if v := variableOrigin(c, "PORT"); v != "file" {
+		t.Errorf("Origin = %q; want file", v)
+	}
+	flags.SetValue("PORT", "3")
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() = nil; want error")
+	}
+	if v := variableOrigin(c, "PORT"); v != "file" {
+		t.Errorf("Origin = %q; want file", v)
+	}
+	flags.SetValue("PORT", "2")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := variableOrigin(c, "PORT"); v != "flags" {
+		t.Errorf("Origin = %q; want flags", v)
+	}
+}
+
+// variableOrigin returns the origin reported by c.Variables for the variable
+// with the given name.
+func variableOrigin(c Config, name string) string {
+	for _, v := range c.Variables() {
+		if v.Name == name {
+			return v.Origin
+		}
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// registerConcurrencyTestVariables registers one variable of each type, with
+// names ending in suffix, and reads each of them back.
+func registerConcurrencyTestVariables(t *testing.T, c Config, suffix string) {
+	t.Helper()
+	for _, err := range []error{
+		c.NewInt("INT_"+suffix, 1, "int"),
+		c.NewString("STRING_"+suffix, "a", "string"),
+		c.NewBool("BOOL_"+suffix, true, "bool"),
+		c.NewDuration("DURATION_"+suffix, time.Second, "duration"),
+		c.NewFloat("FLOAT_"+suffix, 1.5, "float"),
+		c.NewByteSize("BYTE_SIZE_"+suffix, 1024, "byte size"),
+		c.NewStringList("STRING_LIST_"+suffix, []string{"a"}, "string list"),
+		c.NewIntList("INT_LIST_"+suffix, []int{1}, "int list"),
+		c.NewStringMap("STRING_MAP_"+suffix, map[string]string{"a": "b"}, "string map"),
+	} {
+		if err != nil {
+			t.Errorf("New() = %v; want nil", err)
+			return
+		}
+	}
+	if v := c.Int("INT_" + suffix); v != 1 {
+		t.Errorf("Int() = %v; want 1", v)
+	}
+	if v := c.String("STRING_" + suffix); v != "a" {
+		t.Errorf("String() = %v; want a", v)
+	}
+	if v := c.Bool("BOOL_" + suffix); !v {
+		t.Errorf("Bool() = %v; want true", v)
+	}
+	if v := c.Duration("DURATION_" + suffix); v != time.Second {
+		t.Errorf("Duration() = %v; want 1s", v)
+	}
+	if v := c.Float("FLOAT_" + suffix); v != 1.5 {
+		t.Errorf("Float() = %v; want 1.5", v)
+	}
+	if v := c.ByteSize("BYTE_SIZE_" + suffix); v != 1024 {
+		t.Errorf("ByteSize() = %v; want 1024", v)
+	}
+	if v := c.StringList("STRING_LIST_" + suffix); len(v) != 1 {
+		t.Errorf("StringList() = %v; want [a]", v)
+	}
+	if v := c.IntList("INT_LIST_" + suffix); len(v) != 1 {
+		t.Errorf("IntList() = %v; want [1]", v)
+	}
+	if v := c.StringMap("STRING_MAP_" + suffix); len(v) != 1 {
+		t.Errorf("StringMap() = %v; want map[a:b]", v)
+	}
+}
+
+// Test_Concurrent_Registration tests registering and reading variables from
+// several goroutines, while other goroutines enumerate and reload the
+// configuration. Run with -race.
+func Test_Concurrent_Registration(t *testing.T) {
+	t.Parallel()
+	const goroutines = 16
+	c := New(WithSource(newTestSource()), WithCollectErrors())
+	c.Validate(func(Config) error { return nil })
+	var wg sync.WaitGroup
+	for _, read := range []func(){
+		func() { _ = c.Variables() },
+		func() { _ = c.Dump() },
+		func() { _ = c.Err() },
+		func() { _ = c.Check() },
+		func() {
+			if err := c.Reload(); err != nil {
+				t.Errorf("Reload() = %v; want nil", err)
+			}
+		},
+	} {
+		read := read
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				read()
+			}
+		}()
+	}
+	for i := 0; i < goroutines; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registerConcurrencyTestVariables(t, c, fmt.Sprint(i))
+			if err := c.OnChange(fmt.Sprintf("INT_%d", i), func() {}); err != nil {
+				t.Errorf("OnChange() = %v; want nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := len(c.Variables()); v != goroutines*9 {
+		t.Errorf("len(Variables()) = %v; want %v", v, goroutines*9)
+	}
+}
+
+// Test_Concurrent_SameName tests that exactly one of several goroutines
+// registering the same name succeeds.
+func Test_Concurrent_SameName(t *testing.T) {
+	t.Parallel()
+	const goroutines = 16
+	c := New(WithSource(newTestSource()))
+	var succeeded, conflicted int32
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.NewInt("PORT", 8080, "port")
+			switch {
+			case err == nil:
+				atomic.AddInt32(&succeeded, 1)
+			case errors.Is(err, status.ErrAlreadyExists):
+				atomic.AddInt32(&conflicted, 1)
+			default:
+				t.Errorf("NewInt() = %v; want nil or %v", err, status.ErrAlreadyExists)
+			}
+			_ = c.Int("PORT")
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 || conflicted != goroutines-1 {
+		t.Errorf("NewInt() succeeded %d times and conflicted %d times; want 1 and %d", succeeded, conflicted, goroutines-1)
+	}
+	if v := len(c.Variables()); v != 1 {
+		t.Errorf("len(Variables()) = %v; want 1", v)
+	}
+}
+
+// Test_Concurrent_Reload tests reading values while they are reloaded with
+// changing values: readers see either the old or the new value, never a
+// partly applied one. Run with -race.
+func Test_Concurrent_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	c := New(WithSource(src))
+	sub := c.Sub("POOL")
+	if err := sub.NewInt("SIZE", 1, "pool size"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := sub.NewStringList("HOSTS", []string{"a"}, "hosts"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if v := sub.Int("SIZE"); v != 1 && v != 2 {
+					t.Errorf("Int() = %v; want 1 or 2", v)
+				}
+				if v := strings.Join(sub.StringList("HOSTS"), ","); v != "a" && v != "b,c" {
+					t.Errorf("StringList() = %v; want [a] or [b c]", v)
+				}
+				_ = sub.Dump()
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		size, hosts := 1+j%2, "a"
+		if size == 2 {
+			hosts = "b,c"
+		}
+		src.SetValue("POOL_SIZE", fmt.Sprint(size))
+		src.SetValue("POOL_HOSTS", hosts)
+		if err := c.Reload(); err != nil {
+			t.Errorf("Reload() = %v; want nil", err)
+		}
+		if v := sub.Int("SIZE"); v != size {
+			t.Errorf("Int() = %v; want %v", v, size)
+		}
+		if v := strings.Join(sub.StringList("HOSTS"), ","); v != hosts {
+			t.Errorf("StringList() = %v; want %v", v, hosts)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
@@ -14,7 +14,19 @@
 
 package config
 
-// Config holds configuration variables.
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Config holds configuration variables. A Config is safe for concurrent use:
+// variables may be registered while other goroutines read, enumerate, or
+// reload the configuration.
 type Config interface {
 	// NewInt creates a new int variable.
 	NewInt(name string, defaultValue int, description string, options ...IntOption) error
@@ -32,14 +44,106 @@ type Config interface {
 	// DescribeString returns the description of the string variable with the given name. If the variable does not exist, it calls bug.Bug.
 	// If bug.Bug does not panic, DescribeString returns "".
 	DescribeString(name string) string
+	// AllowedValues returns a copy of the set of values allowed for the string variable with the given name, as set by
+	// WithAllowedValues, or nil if any value is allowed. If the variable does not exist, it calls bug.Bug. If bug.Bug does
+	// not panic, AllowedValues returns nil.
+	AllowedValues(name string) []string
 	// NewBool creates a new bool variable.
-	NewBool(name string, defaultValue bool, description string) error
+	NewBool(name string, defaultValue bool, description string, options ...BoolOption) error
 	// Bool returns the value of the bool variable with the given name. If the variable does not exist, it calls bug.Bug.
 	// If bug.Bug does not panic, Bool returns false.
 	Bool(name string) bool
 	// DescribeBool returns the description of the bool variable with the given name. If the variable does not exist, it calls bug.Bug.
 	// If bug.Bug does not panic, DescribeBool returns "".
 	DescribeBool(name string) string
+	// NewDuration creates a new duration variable.
+	NewDuration(name string, defaultValue time.Duration, description string, options ...DurationOption) error
+	// Duration returns the value of the duration variable with the given name. If the variable does not exist, it calls bug.Bug.
+	// If bug.Bug does not panic, Duration returns 0.
+	Duration(name string) time.Duration
+	// DescribeDuration returns the description of the duration variable with the given name. If the variable does not exist, it calls bug.Bug.
+	// If bug.Bug does not panic, DescribeDuration returns "".
+	DescribeDuration(name string) string
+	// NewFloat creates a new float variable.
+	NewFloat(name string, defaultValue float64, description string, options ...FloatOption) error
+	// Float returns the value of the float variable with the given name. If the variable does not exist, it calls bug.Bug.
+	// If bug.Bug does not panic, Float returns 0.
+	Float(name string) float64
+	// DescribeFloat returns the description of the float variable with the given name. If the variable does not exist, it calls bug.Bug.
+	// If bug.Bug does not panic, DescribeFloat returns "".
+	DescribeFloat(name string) string
+	// NewByteSize creates a new byte size variable. Values are parsed with ParseByteSize.
+	NewByteSize(name string, defaultValue int64, description string, options ...ByteSizeOption) error
+	// ByteSize returns the value, in bytes, of the byte size variable with the given name. If the variable does not exist, it
+	// calls bug.Bug. If bug.Bug does not panic, ByteSize returns 0.
+	ByteSize(name string) int64
+	// DescribeByteSize returns the description of the byte size variable with the given name. If the variable does not exist,
+	// it calls bug.Bug. If bug.Bug does not panic, DescribeByteSize returns "".
+	DescribeByteSize(name string) string
+	// NewStringList creates a new string list variable. Values are split into elements with SplitList.
+	NewStringList(name string, defaultValue []string, description string, options ...StringListOption) error
+	// StringList returns a copy of the value of the string list variable with the given name. If the variable does not exist,
+	// it calls bug.Bug. If bug.Bug does not panic, StringList returns nil.
+	StringList(name string) []string
+	// DescribeStringList returns the description of the string list variable with the given name. If the variable does not
+	// exist, it calls bug.Bug. If bug.Bug does not panic, DescribeStringList returns "".
+	DescribeStringList(name string) string
+	// NewIntList creates a new int list variable. Values are parsed with ParseIntList.
+	NewIntList(name string, defaultValue []int, description string, options ...IntListOption) error
+	// IntList returns a copy of the value of the int list variable with the given name. If the variable does not exist, it
+	// calls bug.Bug. If bug.Bug does not panic, IntList returns nil.
+	IntList(name string) []int
+	// DescribeIntList returns the description of the int list variable with the given name. If the variable does not exist,
+	// it calls bug.Bug. If bug.Bug does not panic, DescribeIntList returns "".
+	DescribeIntList(name string) string
+	// NewStringMap creates a new string map variable. Values are parsed with ParseStringMap.
+	NewStringMap(name string, defaultValue map[string]string, description string, options ...StringMapOption) error
+	// StringMap returns a copy of the value of the string map variable with the given name. If the variable does not exist,
+	// it calls bug.Bug. If bug.Bug does not panic, StringMap returns nil.
+	StringMap(name string) map[string]string
+	// DescribeStringMap returns the description of the string map variable with the given name. If the variable does not
+	// exist, it calls bug.Bug. If bug.Bug does not panic, DescribeStringMap returns "".
+	DescribeStringMap(name string) string
+	// Variables returns a description of every registered variable, in
+	// registration order. The values of secrets are replaced by Redacted.
+	Variables() []VariableInfo
+	// Err returns the errors collected while registering variables, as an
+	// Errors, or nil if there are none. Errors are only collected if the
+	// Config was created with WithCollectErrors.
+	Err() error
+	// Dump returns the values of all variables as text, keyed by name. The
+	// values of secrets are replaced by Redacted, so the result is safe to
+	// log.
+	Dump() map[string]string
+	// OnChange registers a function that is called after Reload changes the
+	// value of the variable with the given name. If the variable does not
+	// exist, OnChange returns status.ErrNotFound.
+	OnChange(name string, f func()) error
+	// Reload reloads the values of all variables from the source. Each value
+	// is validated exactly as it was when the variable was registered. If any
+	// value is invalid, Reload returns an Errors and no value is changed.
+	Reload() error
+	// Watch reloads the configuration whenever the source reports a change,
+	// or a secret file registered with WithSecretRotation changes, until ctx
+	// is canceled. onReload, if not nil, is called with the result of each
+	// reload. If the source does not implement Watcher and there are no
+	// rotating secrets, Watch returns status.ErrNotImplemented.
+	Watch(ctx context.Context, onReload func(err error)) error
+	// Validate registers a function that validates the configuration as a
+	// whole, for rules that involve several variables. Validators are run by
+	// Check, and by Reload against the reloaded values before they are
+	// applied. A validator reports a violation by returning an error,
+	// ideally made with Violation; it may return an Errors to report several.
+	Validate(f func(Config) error)
+	// Check runs the validators registered with Validate against the current
+	// values. It returns an Errors holding a *ValidationError for each
+	// violation, or nil if there are none.
+	Check() error
+	// Sub returns a view of the Config whose variable names are prefixed
+	// with prefix and "_", for both registration and loading. Variables
+	// registered through the view belong to this Config, which enumerates
+	// them under their full names.
+	Sub(prefix string) Config
 }
 
 // Source is an interface that loads the values of the configuration variables.
@@ -55,15 +159,125 @@ type Source interface {
 	// LoadBool loads the value of the bool variable with the given name. If the
 	// variable does not exist, it must return false and status.ErrNotFound.
 	LoadBool(name string) (bool, error)
+	// LoadDuration loads the value of the duration variable with the given
+	// name. If the variable does not exist, it must return 0 and
+	// status.ErrNotFound.
+	LoadDuration(name string) (time.Duration, error)
+	// LoadFloat loads the value of the float variable with the given name. If
+	// the variable does not exist, it must return 0 and status.ErrNotFound.
+	LoadFloat(name string) (float64, error)
+	// LoadByteSize loads the value, in bytes, of the byte size variable with
+	// the given name. If the variable does not exist, it must return 0 and
+	// status.ErrNotFound.
+	LoadByteSize(name string) (int64, error)
+	// LoadStringList loads the value of the string list variable with the
+	// given name. Sources that store lists as text split them on sep. If the
+	// variable does not exist, it must return nil and status.ErrNotFound.
+	LoadStringList(name string, sep string) ([]string, error)
+	// LoadIntList loads the value of the int list variable with the given
+	// name. Sources that store lists as text split them on sep. If the
+	// variable does not exist, it must return nil and status.ErrNotFound.
+	LoadIntList(name string, sep string) ([]int, error)
+	// LoadStringMap loads the value of the string map variable with the given
+	// name. Sources that store maps as text split them into pairs on sep, and
+	// each pair into a key and value on kvSep. If the variable does not
+	// exist, it must return nil and status.ErrNotFound.
+	LoadStringMap(name string, sep string, kvSep string) (map[string]string, error)
+}
+
+// Reloader is implemented by sources that cache their values and can read
+// them again.
+type Reloader interface {
+	// Reload reads the values of the source again.
+	Reload() error
+}
+
+// Stager is implemented by reloadable sources that can read their values
+// again without applying them. Config.Reload stages the new values, validates
+// them, and applies them to the source only if they are accepted, so that a
+// rejected reload leaves the source as it was and the same values are
+// rejected again by the next Reload or Watch. Sources that implement only
+// Reloader are reloaded before the values are validated.
+type Stager interface {
+	// Stage reads the values of the source again, without changing the
+	// source. It returns a Source that loads the new values, and a function
+	// that applies them to the source.
+	Stage() (Source, func(), error)
+}
+
+// Watcher is implemented by sources that can signal that their values may
+// have changed.
+type Watcher interface {
+	// Watch calls notify whenever the values of the source may have changed.
+	// It blocks until ctx is canceled.
+	Watch(ctx context.Context, notify func()) error
+}
+
+// variable is a registered configuration variable.
+type variable interface {
+	// load loads the value of the variable from the source and validates it.
+	// If the source does not have a value, load returns the default value.
+	// load does not modify the variable.
+	load(src Source) (interface{}, error)
+	// set sets the value of the variable to a value returned by load. It
+	// returns true if the value changed.
+	set(value interface{}) bool
+	// format returns the value of the variable as text, or Redacted if the
+	// variable is a secret.
+	format() string
+	// text returns a value returned by load as text, formatted as by format
+	// but never redacted.
+	text(value interface{}) string
+	// initialValue returns the default value of the variable, in the form
+	// returned by load.
+	initialValue() interface{}
+	// info returns a description of the variable. Key and Origin are left
+	// for the Config to fill in.
+	info() VariableInfo
+	// legacy returns the legacy names of the variable.
+	legacy() *legacyNames
 }
 
 // configImpl holds the configuration variables.
 type configImpl struct {
-	ints       map[string]*Int
-	strings    map[string]*String
-	bools      map[string]*Bool
-	loadPrefix string
-	source     Source
+	ints        map[string]*Int
+	strings     map[string]*String
+	bools       map[string]*Bool
+	durations   map[string]*Duration
+	floats      map[string]*Float
+	byteSizes   map[string]*ByteSize
+	stringLists map[string]*StringList
+	intLists    map[string]*IntList
+	stringMaps  map[string]*StringMap
+	loadPrefix  string
+	source      Source
+	// vars holds every variable, keyed by name.
+	vars map[string]variable
+	// order holds the names of the variables in registration order.
+	order []string
+	// origins holds, for each variable, the layers of a chain source that
+	// supplied the values its current value was loaded from, keyed by the
+	// name each value was loaded with. Origins are recorded when a value is
+	// set, so that a rejected reload does not change them.
+	origins map[string]map[string]string
+	// onChange holds the change callbacks, keyed by variable name.
+	onChange map[string][]func()
+	// lock guards the values of the variables and onChange.
+	lock sync.RWMutex
+	// reloadLock serializes reloads.
+	reloadLock sync.Mutex
+	// collectErrors is true if errors loading values are collected instead
+	// of being returned by registration.
+	collectErrors bool
+	// errs holds the collected errors.
+	errs Errors
+	// validators holds the validators registered with Validate.
+	validators []func(Config) error
+	// onDeprecated is called when a variable is loaded from a deprecated
+	// name.
+	onDeprecated func(name, deprecated string)
+	// warned holds the deprecated names that onDeprecated was called for.
+	warned map[string]bool
 }
 
 // Option is an option for Config.
@@ -85,19 +299,285 @@ func WithSource(source Source) Option {
 	}
 }
 
+// WithCollectErrors returns an Option that collects the errors that occur
+// when loading the values of variables, so that every missing or invalid
+// value can be reported at once. When a value cannot be loaded, registration
+// succeeds, the variable has its default value, and the error is added to the
+// errors returned by Err. Errors in the registration itself, such as a
+// duplicate name or an invalid default value, are still returned immediately.
+func WithCollectErrors() Option {
+	return func(c *configImpl) {
+		c.collectErrors = true
+	}
+}
+
 // New creates a new Config.
 func New(opts ...Option) Config {
-	c := &configImpl{
-		ints:    make(map[string]*Int),
-		strings: make(map[string]*String),
-		bools:   make(map[string]*Bool),
-	}
+	c := newConfigImpl()
 	for _, opt := range opts {
 		opt(c)
 	}
 	if c.source == nil {
-		c.source = &envSource{}
+		c.source = NewEnvSource()
 	}
 	c.source.SetPrefix(c.loadPrefix)
 	return c
 }
+
+// newConfigImpl returns a configImpl without variables.
+func newConfigImpl() *configImpl {
+	return &configImpl{
+		ints:        make(map[string]*Int),
+		strings:     make(map[string]*String),
+		bools:       make(map[string]*Bool),
+		durations:   make(map[string]*Duration),
+		floats:      make(map[string]*Float),
+		byteSizes:   make(map[string]*ByteSize),
+		stringLists: make(map[string]*StringList),
+		intLists:    make(map[string]*IntList),
+		stringMaps:  make(map[string]*StringMap),
+		vars:        make(map[string]variable),
+		origins:     make(map[string]map[string]string),
+		onChange:    make(map[string][]func()),
+		warned:      make(map[string]bool),
+	}
+}
+
+// checkName returns an error if a variable with the given name already
+// exists.
+func (c *configImpl) checkName(name string) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.checkNameLocked(name)
+}
+
+// checkNameLocked is checkName for callers holding c.lock.
+func (c *configImpl) checkNameLocked(name string) error {
+	if _, ok := c.vars[name]; ok {
+		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
+	}
+	return nil
+}
+
+// register loads the value of a new variable from the source and adds it to
+// the set of variables. The value is loaded without holding c.lock, so that
+// a slow source does not block readers; the name is checked again when the
+// variable is added, in case another goroutine registered it meanwhile.
+func (c *configImpl) register(name string, v variable) error {
+	value, origins, err := c.load(c.source, name, v)
+	if err != nil {
+		if !c.collectErrors {
+			if errors.Is(err, errRequired) {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			return err
+		}
+		err = fmt.Errorf("%s: %w", name, err)
+		value = v.initialValue()
+		origins = nil
+	}
+	v.set(value)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.checkNameLocked(name); err != nil {
+		return err
+	}
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+	c.add(name, v)
+	c.origins[name] = origins
+	return nil
+}
+
+// load loads the value of a variable from src, without modifying the
+// variable. If src tracks origins, load also returns the layers that supplied
+// the values it was loaded from.
+func (c *configImpl) load(src Source, name string, v variable) (interface{}, map[string]string, error) {
+	var origins map[string]string
+	if t, ok := src.(originTracker); ok {
+		origins = make(map[string]string)
+		src = t.trackOrigins(origins)
+	}
+	value, err := v.load(c.legacySource(src, name, v))
+	return value, origins, err
+}
+
+// add adds a variable to the set of variables. The caller must hold c.lock,
+// or be the only user of c.
+func (c *configImpl) add(name string, v variable) {
+	switch v := v.(type) {
+	case *Int:
+		c.ints[name] = v
+	case *String:
+		c.strings[name] = v
+	case *Bool:
+		c.bools[name] = v
+	case *Duration:
+		c.durations[name] = v
+	case *Float:
+		c.floats[name] = v
+	case *ByteSize:
+		c.byteSizes[name] = v
+	case *StringList:
+		c.stringLists[name] = v
+	case *IntList:
+		c.intLists[name] = v
+	case *StringMap:
+		c.stringMaps[name] = v
+	}
+	c.vars[name] = v
+	c.order = append(c.order, name)
+}
+
+// Err returns the errors collected while registering variables.
+func (c *configImpl) Err() error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return append(Errors{}, c.errs...)
+}
+
+// OnChange registers a function that is called after Reload changes the value
+// of the variable with the given name.
+func (c *configImpl) OnChange(name string, f func()) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.vars[name]; !ok {
+		return fmt.Errorf("%w: %s", status.ErrNotFound, name)
+	}
+	c.onChange[name] = append(c.onChange[name], f)
+	return nil
+}
+
+// Reload reloads the values of all variables from the source. The new values
+// are committed, to both the variables and the source, only if every one of
+// them is valid.
+func (c *configImpl) Reload() error {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+	src, commit, err := stage(c.source)
+	if err != nil {
+		return err
+	}
+	c.lock.RLock()
+	order := append([]string{}, c.order...)
+	vars := make([]variable, len(order))
+	for i, name := range order {
+		vars[i] = c.vars[name]
+	}
+	validators := append([]func(Config) error{}, c.validators...)
+	c.lock.RUnlock()
+	values := make([]interface{}, len(vars))
+	origins := make([]map[string]string, len(vars))
+	var errs Errors
+	for i, v := range vars {
+		value, o, err := c.load(src, order[i], v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", order[i], err))
+			continue
+		}
+		values[i] = value
+		origins[i] = o
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if len(validators) > 0 {
+		if err := runValidators(c.snapshot(src, order, vars, values), validators); err != nil {
+			return err
+		}
+	}
+	commit()
+	var callbacks []func()
+	c.lock.Lock()
+	for i, v := range vars {
+		if v.set(values[i]) {
+			callbacks = append(callbacks, c.onChange[order[i]]...)
+		}
+		c.origins[order[i]] = origins[i]
+	}
+	c.lock.Unlock()
+	for _, f := range callbacks {
+		f()
+	}
+	return nil
+}
+
+// stage stages the values of src if it implements Stager, or reloads it if it
+// implements Reloader. It returns the Source to load the new values from, and
+// the function that applies them to src.
+func stage(src Source) (Source, func(), error) {
+	if s, ok := src.(Stager); ok {
+		return s.Stage()
+	}
+	if r, ok := src.(Reloader); ok {
+		if err := r.Reload(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return src, func() {}, nil
+}
+
+// Dump returns the values of all variables as text, keyed by name.
+func (c *configImpl) Dump() map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	values := make(map[string]string, len(c.vars))
+	for name, v := range c.vars {
+		values[name] = v.format()
+	}
+	return values
+}
+
+// Watch reloads the configuration whenever the source reports a change, or a
+// rotating secret file changes.
+func (c *configImpl) Watch(ctx context.Context, onReload func(err error)) error {
+	notify := func() {
+		err := c.Reload()
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+	var watches []func() error
+	if w, ok := c.source.(Watcher); ok {
+		watches = append(watches, func() error {
+			return w.Watch(ctx, notify)
+		})
+	}
+	c.lock.RLock()
+	for _, name := range c.order {
+		if s, ok := c.strings[name]; ok && s.rotationInterval > 0 {
+			s := s
+			watches = append(watches, func() error {
+				return watchSecretFile(ctx, c.source, s, notify)
+			})
+		}
+	}
+	c.lock.RUnlock()
+	if len(watches) == 0 {
+		return status.ErrNotImplemented
+	}
+	return watchAll(watches)
+}
+
+// watchAll runs each watch in its own goroutine and waits for all of them to
+// return. It returns the first error.
+func watchAll(watches []func() error) error {
+	errs := make(chan error, len(watches))
+	for _, watch := range watches {
+		watch := watch
+		go func() {
+			errs <- watch()
+		}()
+	}
+	var err error
+	for range watches {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown writes a Markdown table that documents the given variables,
+// as returned by Config.Variables.
+func WriteMarkdown(w io.Writer, vars []VariableInfo) error {
+	var b strings.Builder
+	b.WriteString("| Variable | Type | Default | Required | Constraints | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, v := range vars {
+		def := ""
+		if v.Default != "" {
+			def = "`" + v.Default + "`"
+		}
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			v.Key,
+			v.Type,
+			markdownEscape(def),
+			required,
+			markdownEscape(strings.Join(docConstraints(v), ", ")),
+			markdownEscape(v.Description))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSONSchema writes a JSON schema that describes an object holding the
+// given variables, as returned by Config.Variables. Values that may be given
+// either natively or as text, such as lists, accept both forms.
+func WriteJSONSchema(w io.Writer, vars []VariableInfo) error {
+	properties := make(map[string]interface{}, len(vars))
+	required := []string{}
+	for _, v := range vars {
+		properties[v.Key] = jsonSchemaProperty(v)
+		if v.Required {
+			required = append(required, v.Key)
+		}
+	}
+	schema := map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+// WriteDotEnv writes a sample .env file that sets each of the given
+// variables, as returned by Config.Variables, to its default value. Each
+// variable is preceded by comments that describe it. Secrets are left empty.
+func WriteDotEnv(w io.Writer, vars []VariableInfo) error {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if v.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", v.Description)
+		}
+		details := []string{"type: " + string(v.Type)}
+		if v.Required {
+			details = append(details, "required")
+		}
+		details = append(details, docConstraints(v)...)
+		fmt.Fprintf(&b, "# %s\n", strings.Join(details, "; "))
+		value := v.Default
+		if v.Secret {
+			value = ""
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Key, dotEnvQuote(value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// docConstraints returns the constraints on a variable for documentation,
+// including whether it is a secret and the names it may be loaded from.
+func docConstraints(v VariableInfo) []string {
+	constraints := v.Constraints()
+	if v.Secret {
+		constraints = append(constraints, "secret")
+	}
+	if len(v.Aliases) > 0 {
+		constraints = append(constraints, "aliases: "+strings.Join(v.Aliases, ", "))
+	}
+	if len(v.DeprecatedNames) > 0 {
+		constraints = append(constraints, "deprecated names: "+strings.Join(v.DeprecatedNames, ", "))
+	}
+	return constraints
+}
+
+// markdownEscape escapes text for a Markdown table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// dotEnvQuote quotes a value for a .env file, if it needs quoting.
+func dotEnvQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\n\"'#$\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`).Replace(s) + `"`
+}
+
+// jsonSchemaProperty returns the JSON schema of a variable.
+func jsonSchemaProperty(v VariableInfo) map[string]interface{} {
+	p := map[string]interface{}{}
+	if v.Description != "" {
+		p["description"] = v.Description
+	}
+	if v.Secret {
+		p["writeOnly"] = true
+	} else {
+		p["default"] = jsonSchemaDefault(v.defaultValue)
+	}
+	switch v.Type {
+	case TypeInt:
+		p["type"] = "integer"
+		setNumber(p, "minimum", v.Minimum)
+		setNumber(p, "maximum", v.Maximum)
+	case TypeFloat:
+		p["type"] = "number"
+		setNumber(p, "minimum", v.Minimum)
+		setNumber(p, "maximum", v.Maximum)
+	case TypeByteSize:
+		p["type"] = []string{"integer", "string"}
+		setNumber(p, "minimum", v.Minimum)
+		setNumber(p, "maximum", v.Maximum)
+	case TypeBool:
+		p["type"] = "boolean"
+	case TypeDuration:
+		p["type"] = "string"
+	case TypeString:
+		p["type"] = "string"
+		if v.AllowedValues != nil && !v.CaseInsensitive {
+			p["enum"] = v.AllowedValues
+		}
+	case TypeStringList, TypeIntList:
+		items := "string"
+		if v.Type == TypeIntList {
+			items = "integer"
+		}
+		p["type"] = []string{"array", "string"}
+		p["items"] = map[string]interface{}{"type": items}
+		setNumber(p, "minItems", v.MinimumLength)
+		setNumber(p, "maxItems", v.MaximumLength)
+	case TypeStringMap:
+		p["type"] = []string{"object", "string"}
+		p["additionalProperties"] = map[string]interface{}{"type": "string"}
+		setNumber(p, "minProperties", v.MinimumLength)
+		setNumber(p, "maxProperties", v.MaximumLength)
+	}
+	return p
+}
+
+// jsonSchemaDefault returns a default value in the form used by the JSON
+// schema.
+func jsonSchemaDefault(value interface{}) interface{} {
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+	return value
+}
+
+// setNumber sets key in p to the number n, if n is not empty.
+func setNumber(p map[string]interface{}, key, n string) {
+	if n == "" {
+		return
+	}
+	if _, err := strconv.ParseFloat(n, 64); err == nil {
+		p[key] = json.Number(n)
+	}
+}
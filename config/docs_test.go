@@ -0,0 +1,132 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newDocsConfig returns a Config with variables to document.
+func newDocsConfig(t *testing.T) Config {
+	t.Helper()
+	src := newTestSource()
+	src.SetValue("PASSWORD", "hunter2")
+	c := New(WithSource(src))
+	for _, err := range []error{
+		c.NewInt("PORT", 8080, "Port to listen on.", WithMinimumValue(1)),
+		c.NewString("LOG_FORMAT", "json", "Log format | style.", WithAllowedValues("json", "console")),
+		c.NewString("PASSWORD", "", "Database password.", WithSecret(), WithRequired()),
+		c.NewStringList("ORIGINS", []string{"http://a", "http://b c"}, "", WithStringListSeparator(" ")),
+	} {
+		if err != nil {
+			t.Fatalf("New*() = %v; want nil", err)
+		}
+	}
+	return c
+}
+
+// Test_WriteMarkdown tests that WriteMarkdown() writes a table of variables.
+func Test_WriteMarkdown(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, newDocsConfig(t).Variables()); err != nil {
+		t.Fatalf("WriteMarkdown() = %v; want nil", err)
+	}
+	expected := "| Variable | Type | Default | Required | Constraints | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `PORT` | int | `8080` | no | >= 1 | Port to listen on. |\n" +
+		"| `LOG_FORMAT` | string | `json` | no | one of: json, console | Log format \\| style. |\n" +
+		"| `PASSWORD` | string |  | yes | secret | Database password. |\n" +
+		"| `ORIGINS` | stringlist | `http://a http://b c` | no | separated by \" \" |  |\n"
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Test_WriteDotEnv tests that WriteDotEnv() writes a sample .env file.
+func Test_WriteDotEnv(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	if err := WriteDotEnv(&b, newDocsConfig(t).Variables()); err != nil {
+		t.Fatalf("WriteDotEnv() = %v; want nil", err)
+	}
+	expected := `# Port to listen on.
+# type: int; >= 1
+PORT=8080
+
+# Log format | style.
+# type: string; one of: json, console
+LOG_FORMAT=json
+
+# Database password.
+# type: string; required; secret
+PASSWORD=
+
+# type: stringlist; separated by " "
+ORIGINS="http://a http://b c"
+`
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("WriteDotEnv() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Test_WriteJSONSchema tests that WriteJSONSchema() writes a JSON schema.
+func Test_WriteJSONSchema(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	if err := WriteJSONSchema(&b, newDocsConfig(t).Variables()); err != nil {
+		t.Fatalf("WriteJSONSchema() = %v; want nil", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &schema); err != nil {
+		t.Fatalf("Unmarshal() = %v; want nil", err)
+	}
+	expected := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"PORT": map[string]interface{}{
+				"description": "Port to listen on.",
+				"type":        "integer",
+				"default":     8080.0,
+				"minimum":     1.0,
+			},
+			"LOG_FORMAT": map[string]interface{}{
+				"description": "Log format | style.",
+				"type":        "string",
+				"default":     "json",
+				"enum":        []interface{}{"json", "console"},
+			},
+			"PASSWORD": map[string]interface{}{
+				"description": "Database password.",
+				"type":        "string",
+				"writeOnly":   true,
+			},
+			"ORIGINS": map[string]interface{}{
+				"type":    []interface{}{"array", "string"},
+				"items":   map[string]interface{}{"type": "string"},
+				"default": []interface{}{"http://a", "http://b c"},
+			},
+		},
+		"required": []interface{}{"PASSWORD"},
+	}
+	if diff := cmp.Diff(expected, schema); diff != "" {
+		t.Errorf("WriteJSONSchema() mismatch (-want +got):\n%s", diff)
+	}
+}
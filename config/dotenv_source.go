@@ -0,0 +1,269 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// dotEnvSource is a source that reads from a dotenv file.
+type dotEnvSource struct {
+	textLoader
+	// path is the path of the file.
+	path string
+	// prefix is the prefix for the names of the variables.
+	prefix string
+	// lock guards values.
+	lock sync.RWMutex
+	// values holds the values read from the file, keyed by name.
+	values map[string]string
+}
+
+var _ Source = (*dotEnvSource)(nil)
+var _ Reloader = (*dotEnvSource)(nil)
+var _ Stager = (*dotEnvSource)(nil)
+
+// NewDotEnvSource creates a Source that reads configuration variables from a
+// dotenv file, such as a .env file used for local development. The file is
+// read when the source is created, and again on each Reload. Values are
+// parsed from their text in the same way as environment variables, and the
+// prefix set with SetPrefix is prepended to the variable name before lookup.
+//
+// Each line of the file is blank, a comment starting with '#', or an
+// assignment NAME=value, optionally preceded by "export ". Values may be:
+//
+//   - unquoted: surrounding whitespace is trimmed, and a '#' preceded by
+//     whitespace starts a comment.
+//   - single-quoted: the value is taken literally.
+//   - double-quoted: the escapes \n, \r, \t, \", \\ and \$ are recognized.
+//
+// In unquoted and double-quoted values, ${OTHER} is replaced by the value of
+// OTHER: from the environment if it is set there, otherwise from an earlier
+// line of the file, otherwise the empty string. This matches the precedence
+// of the environment over the file when the source is layered beneath
+// NewEnvSource with NewChainSource.
+func NewDotEnvSource(path string) (Source, error) {
+	s := &dotEnvSource{path: path}
+	s.textLoader = s.lookup
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetPrefix sets the prefix for the names of the variables.
+func (s *dotEnvSource) SetPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Reload reads the file again. If the file cannot be read, the previous
+// values are kept.
+func (s *dotEnvSource) Reload() error {
+	_, commit, err := s.Stage()
+	if err != nil {
+		return err
+	}
+	commit()
+	return nil
+}
+
+// Stage reads the file again, without changing the source. It returns a
+// source holding the new values, and a function that applies them.
+func (s *dotEnvSource) Stage() (Source, func(), error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := parseDotEnv(string(data), os.LookupEnv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	staged := &dotEnvSource{path: s.path, prefix: s.prefix, values: values}
+	staged.textLoader = staged.lookup
+	return staged, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.values = values
+	}, nil
+}
+
+// lookup returns the value of the variable with the given name.
+func (s *dotEnvSource) lookup(name string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, ok := s.values[s.prefix+name]
+	return v, ok
+}
+
+// parseDotEnv parses the contents of a dotenv file. References to other
+// variables are looked up with lookupEnv first, then among the values
+// already parsed.
+func parseDotEnv(data string, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	values := make(map[string]string)
+	lookup := func(name string) string {
+		if v, ok := lookupEnv(name); ok {
+			return v
+		}
+		return values[name]
+	}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest := strings.TrimPrefix(line, "export"); rest != line && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isDotEnvName(name) {
+			return nil, fmt.Errorf("%w: line %d: expected NAME=value", status.ErrInvalidArgument, i+1)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(value), lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", status.ErrInvalidArgument, i+1, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// isDotEnvName returns true if name is a valid variable name.
+func isDotEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		case r == '.' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseDotEnvValue parses the value of an assignment, with surrounding
+// whitespace removed.
+func parseDotEnvValue(s string, lookup func(string) string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		if err := checkDotEnvTrailer(s[end+2:]); err != nil {
+			return "", err
+		}
+		return s[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '"':
+				if err := checkDotEnvTrailer(s[i+1:]); err != nil {
+					return "", err
+				}
+				return b.String(), nil
+			case '\\':
+				if i+1 == len(s) {
+					return "", fmt.Errorf("unterminated double-quoted value")
+				}
+				i++
+				switch e := s[i]; e {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\', '$':
+					b.WriteByte(e)
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(e)
+				}
+			case '$':
+				n, err := expandDotEnvReference(&b, s[i:], lookup)
+				if err != nil {
+					return "", err
+				}
+				i += n - 1
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			s = strings.TrimSpace(s[:i])
+			break
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+		n, err := expandDotEnvReference(&b, s[i:], lookup)
+		if err != nil {
+			return "", err
+		}
+		i += n - 1
+	}
+	return b.String(), nil
+}
+
+// expandDotEnvReference writes the expansion of the reference at the start of
+// s, which begins with '$', to b, and returns the number of bytes consumed.
+// A '$' that does not start a ${NAME} reference is written as is.
+func expandDotEnvReference(b *strings.Builder, s string, lookup func(string) string) (int, error) {
+	if !strings.HasPrefix(s, "${") {
+		b.WriteByte('$')
+		return 1, nil
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return 0, fmt.Errorf("unterminated reference %q", s)
+	}
+	name := s[2:end]
+	if !isDotEnvName(name) {
+		return 0, fmt.Errorf("invalid reference %q", s[:end+1])
+	}
+	b.WriteString(lookup(name))
+	return end + 1, nil
+}
+
+// checkDotEnvTrailer returns an error unless s, the text after a quoted
+// value, is empty or a comment.
+func checkDotEnvTrailer(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "#") {
+		return nil
+	}
+	return fmt.Errorf("unexpected text %q after quoted value", s)
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_ParseDotEnv_Case is a test case for Test_ParseDotEnv.
+type Test_ParseDotEnv_Case struct {
+	input    string
+	expected map[string]string
+	err      error
+}
+
+// Test_ParseDotEnv tests parsing the contents of dotenv files.
+func Test_ParseDotEnv(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{"HOME": "/home/dev", "MESSAGE": "from env"}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	for _, tc := range []Test_ParseDotEnv_Case{
+		{input: "", expected: map[string]string{}},
+		{input: "# comment\n\n  # indented comment\n", expected: map[string]string{}},
+		{input: "PORT=8080", expected: map[string]string{"PORT": "8080"}},
+		{input: "  PORT = 8080  \r\n", expected: map[string]string{"PORT": "8080"}},
+		{input: "export PORT=8080", expected: map[string]string{"PORT": "8080"}},
+		{input: "exporter=1", expected: map[string]string{"exporter": "1"}},
+		{input: "EMPTY=", expected: map[string]string{"EMPTY": ""}},
+		{input: "URL=http://host/#anchor # comment", expected: map[string]string{"URL": "http://host/#anchor"}},
+		{input: "A=b=c", expected: map[string]string{"A": "b=c"}},
+		{input: `SINGLE='a ${HOME} \n # b' # comment`, expected: map[string]string{"SINGLE": `a ${HOME} \n # b`}},
+		{input: `DOUBLE="a\tb\nc \"q\" \\ \$HOME # d"`, expected: map[string]string{"DOUBLE": "a\tb\nc \"q\" \\ $HOME # d"}},
+		{input: `PATHS="${HOME}/bin:$PATH"`, expected: map[string]string{"PATHS": "/home/dev/bin:$PATH"}},
+		{input: "A=1\nB=${A}2\nC=\"${B}3\"", expected: map[string]string{"A": "1", "B": "12", "C": "123"}},
+		{input: "MESSAGE=from file\nGREETING=${MESSAGE}!", expected: map[string]string{"MESSAGE": "from file", "GREETING": "from env!"}},
+		{input: "A=${MISSING}x", expected: map[string]string{"A": "x"}},
+		{input: "A=1\nA=2", expected: map[string]string{"A": "2"}},
+		{input: "NOT AN ASSIGNMENT", err: status.ErrInvalidArgument},
+		{input: "=value", err: status.ErrInvalidArgument},
+		{input: "1A=value", err: status.ErrInvalidArgument},
+		{input: "BAD NAME=value", err: status.ErrInvalidArgument},
+		{input: `A="unterminated`, err: status.ErrInvalidArgument},
+		{input: `A='unterminated`, err: status.ErrInvalidArgument},
+		{input: `A="value" trailing`, err: status.ErrInvalidArgument},
+		{input: "A=${UNTERMINATED", err: status.ErrInvalidArgument},
+		{input: "A=${BAD NAME}", err: status.ErrInvalidArgument},
+	} {
+		v, err := parseDotEnv(tc.input, lookupEnv)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("parseDotEnv(%q) = %v, %v; want %v", tc.input, v, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDotEnv(%q) = %v; want nil", tc.input, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, v); diff != "" {
+			t.Errorf("parseDotEnv(%q) mismatch (-want +got):\n%s", tc.input, diff)
+		}
+	}
+}
+
+// writeDotEnvFile writes a dotenv file and returns its path.
+func writeDotEnvFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	return path
+}
+
+// Test_DotEnvSource tests loading variables from a dotenv file.
+func Test_DotEnvSource(t *testing.T) {
+	t.Parallel()
+	path := writeDotEnvFile(t, "# Local settings\nexport APP_PORT=9090\nAPP_HOSTS=\"a, b\"\nAPP_DEV_MODE=true\n")
+	src, err := NewDotEnvSource(path)
+	if err != nil {
+		t.Fatalf("NewDotEnvSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src), WithLoadPrefix("APP_"))
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewStringList("HOSTS", nil, "hosts"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if err := c.NewBool("DEV_MODE", false, "development mode"); err != nil {
+		t.Fatalf("NewBool() = %v; want nil", err)
+	}
+	if err := c.NewString("MISSING", "default", "missing"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+	if v := c.StringList("HOSTS"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("StringList() = %v; want [a b]", v)
+	}
+	if v := c.Bool("DEV_MODE"); !v {
+		t.Errorf("Bool() = %v; want true", v)
+	}
+	if v := c.String("MISSING"); v != "default" {
+		t.Errorf("String() = %v; want default", v)
+	}
+	if err := os.WriteFile(path, []byte("APP_PORT=9191\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9191 {
+		t.Errorf("Int() = %v; want 9191", v)
+	}
+}
+
+// Test_DotEnvSource_BeneathEnv tests layering a dotenv file beneath the
+// environment.
+func Test_DotEnvSource_BeneathEnv(t *testing.T) {
+	t.Setenv("TEST_DOTENV_MESSAGE", "from env")
+	path := writeDotEnvFile(t, "TEST_DOTENV_MESSAGE=from file\nTEST_DOTENV_PORT=9090\n")
+	src, err := NewDotEnvSource(path)
+	if err != nil {
+		t.Fatalf("NewDotEnvSource() = %v; want nil", err)
+	}
+	chain := NewChainSource(
+		Layer{Name: "env", Source: NewEnvSource()},
+		Layer{Name: "dotenv", Source: src},
+	)
+	c := New(WithSource(chain))
+	if err := c.NewString("TEST_DOTENV_MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewInt("TEST_DOTENV_PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := c.String("TEST_DOTENV_MESSAGE"); v != "from env" {
+		t.Errorf("String() = %v; want from env", v)
+	}
+	if v := c.Int("TEST_DOTENV_PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+	if v := variableOrigin(c, "TEST_DOTENV_PORT"); v != "dotenv" {
+		t.Errorf("Origin = %q; want dotenv", v)
+	}
+}
+
+// Test_DotEnvSource_WriteDotEnv tests that a file written by WriteDotEnv
+// loads the values it was written with.
+func Test_DotEnvSource_WriteDotEnv(t *testing.T) {
+	t.Parallel()
+	vars := []VariableInfo{
+		{Key: "MESSAGE", Type: TypeString, Description: "A message.", Default: `say "hi" for $5 # really`},
+		{Key: "LINES", Type: TypeString, Default: "a\\b\nc"},
+		{Key: "PORT", Type: TypeInt, Default: "8080"},
+	}
+	var b bytes.Buffer
+	if err := WriteDotEnv(&b, vars); err != nil {
+		t.Fatalf("WriteDotEnv() = %v; want nil", err)
+	}
+	src, err := NewDotEnvSource(writeDotEnvFile(t, b.String()))
+	if err != nil {
+		t.Fatalf("NewDotEnvSource() = %v; want nil", err)
+	}
+	for _, v := range vars {
+		if got, err := src.LoadString(v.Key); err != nil || got != v.Default {
+			t.Errorf("LoadString(%q) = %q, %v; want %q, nil", v.Key, got, err, v.Default)
+		}
+	}
+}
+
+// Test_DotEnvSource_Errors tests that missing and invalid files are errors.
+func Test_DotEnvSource_Errors(t *testing.T) {
+	t.Parallel()
+	if _, err := NewDotEnvSource(filepath.Join(t.TempDir(), "missing.env")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewDotEnvSource() = %v; want %v", err, os.ErrNotExist)
+	}
+	if _, err := NewDotEnvSource(writeDotEnvFile(t, "NOT AN ASSIGNMENT\n")); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewDotEnvSource() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
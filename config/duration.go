@@ -0,0 +1,218 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Duration is a configuration value that is a duration.
+type Duration struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value time.Duration
+	// defaultValue is the default value of the variable.
+	defaultValue time.Duration
+	// description is the description of the variable.
+	description string
+	// minimumValue is the minimum value of the variable.
+	minimumValue time.Duration
+	// maximumValue is the maximum value of the variable.
+	maximumValue time.Duration
+	// clampValue is true if the value should be clamped to the minimum and maximum values.
+	clampValue bool
+	// validator is the validator for the variable.
+	validator func(time.Duration) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// DurationOption is an option for a duration variable.
+type DurationOption interface {
+	// applyDuration applies the option to a duration variable.
+	applyDuration(c *configImpl, d *Duration) error
+}
+
+// durationOption is a DurationOption implemented by a function.
+type durationOption func(*configImpl, *Duration) error
+
+// applyDuration implements DurationOption.
+func (o durationOption) applyDuration(c *configImpl, d *Duration) error {
+	return o(c, d)
+}
+
+// WithMinimumDuration returns an option that sets the minimum value for a duration variable.
+func WithMinimumDuration(min time.Duration) DurationOption {
+	return durationOption(func(c *configImpl, d *Duration) error {
+		d.minimumValue = min
+		return nil
+	})
+}
+
+// WithMaximumDuration returns an option that sets the maximum value for a duration variable.
+func WithMaximumDuration(max time.Duration) DurationOption {
+	return durationOption(func(c *configImpl, d *Duration) error {
+		d.maximumValue = max
+		return nil
+	})
+}
+
+// WithDurationValidator returns an option that sets the validator for a duration variable.
+func WithDurationValidator(f func(time.Duration) error) DurationOption {
+	return durationOption(func(c *configImpl, d *Duration) error {
+		d.validator = f
+		return nil
+	})
+}
+
+// WithDurationClamping returns an option that enables clamping for a duration variable.
+func WithDurationClamping() DurationOption {
+	return durationOption(func(c *configImpl, d *Duration) error {
+		d.clampValue = true
+		return nil
+	})
+}
+
+// NewDuration creates a new duration variable.
+func (c *configImpl) NewDuration(name string, defaultValue time.Duration, description string, options ...DurationOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	d := &Duration{
+		name:         name,
+		defaultValue: defaultValue,
+		description:  description,
+		minimumValue: math.MinInt64,
+		maximumValue: math.MaxInt64,
+	}
+	for _, opt := range options {
+		if err := opt.applyDuration(c, d); err != nil {
+			return err
+		}
+	}
+	if d.minimumValue > d.maximumValue {
+		return status.ErrInvalidRange
+	}
+	var err error
+	d.defaultValue, err = clampOrError(d.defaultValue, d.minimumValue, d.maximumValue, d.clampValue)
+	if err != nil {
+		return err
+	}
+	if d.validator != nil {
+		if err := d.validator(d.defaultValue); err != nil {
+			return err
+		}
+	}
+	return c.register(name, d)
+}
+
+// load implements variable.
+func (d *Duration) load(src Source) (interface{}, error) {
+	v, err := src.LoadDuration(d.name)
+	if errors.Is(err, status.ErrNotFound) {
+		if d.required {
+			return nil, errRequired
+		}
+		return d.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v, err = clampOrError(v, d.minimumValue, d.maximumValue, d.clampValue)
+	if err != nil {
+		return nil, err
+	}
+	if d.validator != nil {
+		if err := d.validator(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (d *Duration) set(value interface{}) bool {
+	old := d.value
+	d.value = value.(time.Duration)
+	return d.value != old
+}
+
+// format implements variable.
+func (d *Duration) format() string {
+	return d.text(d.value)
+}
+
+// text implements variable.
+func (d *Duration) text(value interface{}) string {
+	return value.(time.Duration).String()
+}
+
+// initialValue implements variable.
+func (d *Duration) initialValue() interface{} {
+	return d.defaultValue
+}
+
+// info implements variable.
+func (d *Duration) info() VariableInfo {
+	info := VariableInfo{
+		Name:         d.name,
+		Type:         TypeDuration,
+		Description:  d.description,
+		Default:      d.defaultValue.String(),
+		Value:        d.format(),
+		Required:     d.required,
+		Clamped:      d.clampValue,
+		defaultValue: d.defaultValue,
+	}
+	if d.minimumValue != math.MinInt64 {
+		info.Minimum = d.minimumValue.String()
+	}
+	if d.maximumValue != math.MaxInt64 {
+		info.Maximum = d.maximumValue.String()
+	}
+	return info
+}
+
+// Duration returns the value of the duration variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, Duration returns 0.
+func (c *configImpl) Duration(name string) time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if d, ok := c.durations[name]; ok {
+		return d.value
+	}
+	defer bug.Bugf("config: duration variable %q does not exist", name)
+	return 0
+}
+
+// DescribeDuration returns the description of the duration variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, DescribeDuration returns "".
+func (c *configImpl) DescribeDuration(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if d, ok := c.durations[name]; ok {
+		return d.description
+	}
+	defer bug.Bugf("config: duration variable %q does not exist", name)
+	return ""
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_NewDuration_Case is a test case for NewDuration.
+type Test_NewDuration_Case struct {
+	// name is the name of the test case.
+	name string
+	// defaultValue is the default value of the variable.
+	defaultValue time.Duration
+	// options are the options for the variable.
+	options []DurationOption
+	// expected is the expected result.
+	expected *Duration
+	// err is the expected error.
+	err error
+}
+
+// Test_NewDuration tests the NewDuration function.
+func Test_NewDuration(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_NewDuration_Case{
+		{
+			name:         "basic case",
+			defaultValue: time.Second,
+			expected: &Duration{
+				name:         "test",
+				value:        time.Second,
+				defaultValue: time.Second,
+				description:  "test",
+				minimumValue: math.MinInt64,
+				maximumValue: math.MaxInt64,
+			},
+		},
+		{
+			name:         "with minimum value, default value less than minimum value",
+			defaultValue: time.Second,
+			options:      []DurationOption{WithMinimumDuration(2 * time.Second)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with minimum value, default value less than minimum value, with clamping",
+			defaultValue: time.Second,
+			options:      []DurationOption{WithMinimumDuration(2 * time.Second), WithDurationClamping()},
+			expected: &Duration{
+				name:         "test",
+				value:        2 * time.Second,
+				defaultValue: 2 * time.Second,
+				description:  "test",
+				minimumValue: 2 * time.Second,
+				maximumValue: math.MaxInt64,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value",
+			defaultValue: 3 * time.Second,
+			options:      []DurationOption{WithMaximumDuration(2 * time.Second)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value, with clamping",
+			defaultValue: 3 * time.Second,
+			options:      []DurationOption{WithMaximumDuration(2 * time.Second), WithDurationClamping()},
+			expected: &Duration{
+				name:         "test",
+				value:        2 * time.Second,
+				defaultValue: 2 * time.Second,
+				description:  "test",
+				minimumValue: math.MinInt64,
+				maximumValue: 2 * time.Second,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with validator, default value invalid",
+			defaultValue: time.Second,
+			options: []DurationOption{WithDurationValidator(func(v time.Duration) error {
+				return status.ErrInvalidArgument
+			})},
+			err: status.ErrInvalidArgument,
+		},
+		{
+			name:         "minimum value greater than maximum value",
+			defaultValue: 2 * time.Second,
+			options:      []DurationOption{WithMinimumDuration(3 * time.Second), WithMaximumDuration(time.Second)},
+			err:          status.ErrInvalidRange,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(WithSource(newNullSource())).(*configImpl)
+			err := c.NewDuration("test", tc.defaultValue, "test", tc.options...)
+			if err != nil {
+				if tc.err == nil {
+					t.Errorf("NewDuration() = %v; want nil", err)
+				} else if !errors.Is(err, tc.err) {
+					t.Errorf("NewDuration() = %v; want %v", err, tc.err)
+				}
+				return
+			}
+			if tc.err != nil {
+				t.Errorf("NewDuration() = nil; want %v", tc.err)
+				return
+			}
+			opts := []cmp.Option{
+				cmp.AllowUnexported(Duration{}, legacyNames{}),
+				cmpopts.IgnoreFields(Duration{}, "validator"),
+			}
+			if diff := cmp.Diff(tc.expected, c.durations["test"], opts...); diff != "" {
+				t.Errorf("NewDuration() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_NewDuration_AlreadyExists tests that NewDuration() returns an error if the
+// variable name already exists.
+func Test_NewDuration_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewDuration("test", time.Second, "test"); err != nil {
+		t.Fatalf("NewDuration() = %v; want nil", err)
+	}
+	if err := c.NewDuration("test", time.Second, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewDuration() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_Duration_FromTestSource tests that Duration() returns the value set in the test
+// source, and that the value is range checked, clamped, and validated.
+func Test_Duration_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", "2s")
+	src.SetValue("clamped", "3s")
+	src.SetValue("invalid", "-1s")
+	src.SetValue("malformed", "abc")
+	c := New(WithSource(src))
+	if err := c.NewDuration("test", time.Second, "test"); err != nil {
+		t.Fatalf("NewDuration() = %v; want nil", err)
+	}
+	if v := c.Duration("test"); v != 2*time.Second {
+		t.Errorf("Duration() = %v; want %v", v, 2*time.Second)
+	}
+	if err := c.NewDuration("clamped", time.Second, "test", WithMaximumDuration(2*time.Second)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewDuration() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewDuration("clamped", time.Second, "test", WithMaximumDuration(2*time.Second), WithDurationClamping()); err != nil {
+		t.Fatalf("NewDuration() = %v; want nil", err)
+	}
+	if v := c.Duration("clamped"); v != 2*time.Second {
+		t.Errorf("Duration() = %v; want %v", v, 2*time.Second)
+	}
+	validator := WithDurationValidator(func(v time.Duration) error {
+		if v < 0 {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})
+	if err := c.NewDuration("invalid", time.Second, "test", WithMinimumDuration(-time.Second*2), validator); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewDuration() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewDuration("malformed", time.Second, "test"); err == nil {
+		t.Errorf("NewDuration() = nil; want error")
+	}
+}
+
+// Test_Duration_FromErrorSource tests that NewDuration() returns the error returned by
+// the source.
+func Test_Duration_FromErrorSource(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newErrorSource(status.ErrInvalidArgument)))
+	if err := c.NewDuration("test", time.Second, "test"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewDuration() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_Duration_FromEnv tests that Duration() returns the value set in the environment.
+func Test_Duration_FromEnv(t *testing.T) {
+	t.Setenv("prefix-test", "2s")
+	c := New(WithLoadPrefix("prefix-"))
+	if err := c.NewDuration("test", time.Second, "test"); err != nil {
+		t.Fatalf("NewDuration() = %v; want nil", err)
+	}
+	if v := c.Duration("test"); v != 2*time.Second {
+		t.Errorf("Duration() = %v; want %v", v, 2*time.Second)
+	}
+}
+
+// Test_Duration_Unknown tests that Duration() and DescribeDuration() call bug.Bug if the
+// variable name is unknown.
+func Test_Duration_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: duration variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.Duration("test")
+	_ = c.DescribeDuration("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeDuration tests that DescribeDuration() returns the correct value.
+func Test_DescribeDuration(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewDuration("test", time.Second, "description"); err != nil {
+		t.Fatalf("NewDuration() = %v; want nil", err)
+	}
+	if v := c.DescribeDuration("test"); v != "description" {
+		t.Errorf("DescribeDuration() = %v; want description", v)
+	}
+}
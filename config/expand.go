@@ -0,0 +1,125 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// WithExpansion returns an option that expands references to other
+// variables in the value of a string variable. Each ${NAME} in the value, or
+// in the default value, is replaced by the value of NAME:
+//
+//   - if NAME is a registered variable, its value in the source, or its
+//     default value, checked as when NAME is registered: clamped, normalized
+//     to an allowed value, and validated. The value is formatted as text as
+//     in Dump. References in the value of NAME are expanded if NAME was
+//     registered with WithExpansion. If NAME is a secret, the variable must
+//     be a secret too (see WithSecret), so that the value of NAME is never
+//     shown in plain text.
+//   - otherwise, the value of NAME in the source, with its references
+//     expanded.
+//
+// NAME is the full name of the variable, without the load prefix. "$$"
+// stands for a literal "$". A reference that cannot be resolved, or a cycle
+// of references, is an error when the variable is registered or reloaded.
+//
+// For example, with DB_USER=app and DB_HOST=db in the environment,
+// "postgres://${DB_USER}@${DB_HOST}/app" expands to "postgres://app@db/app".
+func WithExpansion() StringOption {
+	return stringOption(func(c *configImpl, s *String) error {
+		s.expander = c
+		return nil
+	})
+}
+
+// expand expands the references in s. stack holds the names of the
+// variables being expanded, to detect cycles, and secret is true if the
+// variable at the bottom of the stack is a secret.
+func (c *configImpl) expand(s string, src Source, stack []string, secret bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] != '$':
+			b.WriteByte(s[i])
+		case strings.HasPrefix(s[i:], "$$"):
+			b.WriteByte('$')
+			i++
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated reference %q", status.ErrInvalidArgument, s[i:])
+			}
+			name := s[i+2 : i+end]
+			if name == "" {
+				return "", fmt.Errorf("%w: empty reference", status.ErrInvalidArgument)
+			}
+			value, err := c.resolve(name, src, stack, secret)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve returns the expanded value of the variable with the given name.
+// A registered variable is loaded and checked as when it is registered, and
+// formatted as text. A secret can only be referenced from a secret, so that
+// its value does not show up in the value of another variable.
+func (c *configImpl) resolve(name string, src Source, stack []string, secret bool) (string, error) {
+	for _, n := range stack {
+		if n == name {
+			return "", fmt.Errorf("%w: reference cycle %s -> %s", status.ErrInvalidArgument, strings.Join(stack, " -> "), name)
+		}
+	}
+	c.lock.RLock()
+	v := c.vars[name]
+	c.lock.RUnlock()
+	if v == nil {
+		text, err := src.LoadString(name)
+		if errors.Is(err, status.ErrNotFound) {
+			return "", fmt.Errorf("%w: unresolved reference ${%s}", status.ErrNotFound, name)
+		}
+		if err != nil {
+			return "", fmt.Errorf("${%s}: %w", name, err)
+		}
+		return c.expand(text, src, append(stack[:len(stack):len(stack)], name), secret)
+	}
+	s, _ := v.(*String)
+	if s != nil && s.secret && !secret {
+		return "", fmt.Errorf("%w: ${%s} is a secret, and can only be referenced from a secret", status.ErrInvalidArgument, name)
+	}
+	load := c.legacySource(src, name, v)
+	var value interface{}
+	var err error
+	if s != nil {
+		value, err = s.loadExpanded(load, stack, secret)
+	} else {
+		value, err = v.load(load)
+	}
+	if err != nil {
+		return "", fmt.Errorf("${%s}: %w", name, err)
+	}
+	return v.text(value), nil
+}
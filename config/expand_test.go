@@ -0,0 +1,290 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_Expansion_Case is a test case for Test_Expansion.
+type Test_Expansion_Case struct {
+	// name is the name of the test case.
+	name string
+	// values are the values in the source.
+	values map[string]string
+	// setup registers other variables before URL.
+	setup func(c Config) error
+	// defaultValue is the default value of URL.
+	defaultValue string
+	// expected is the expected value of URL.
+	expected string
+	// err is the expected registration error.
+	err error
+}
+
+// Test_Expansion tests expanding references in the value of a string
+// variable.
+func Test_Expansion(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_Expansion_Case{
+		{
+			name:     "source",
+			values:   map[string]string{"URL": "postgres://${DB_USER}@${DB_HOST}/app", "DB_USER": "app", "DB_HOST": "db"},
+			expected: "postgres://app@db/app",
+		},
+		{
+			name:         "default",
+			values:       map[string]string{"DB_HOST": "db"},
+			defaultValue: "postgres://${DB_HOST}/app",
+			expected:     "postgres://db/app",
+		},
+		{
+			name:   "registered-default",
+			values: map[string]string{"URL": "${DB_HOST}:${DB_PORT}"},
+			setup: func(c Config) error {
+				if err := c.NewString("DB_HOST", "localhost", "host"); err != nil {
+					return err
+				}
+				return c.NewInt("DB_PORT", 5432, "port")
+			},
+			expected: "localhost:5432",
+		},
+		{
+			name:   "registered-checked",
+			values: map[string]string{"URL": ":${PORT}/${MODE}", "PORT": "99999", "MODE": "JSON"},
+			setup: func(c Config) error {
+				if err := c.NewInt("PORT", 80, "port", WithMaximumValue(1000), WithClamping()); err != nil {
+					return err
+				}
+				return c.NewString("MODE", "text", "mode", WithAllowedValues("text", "json"), WithCaseInsensitiveValues())
+			},
+			expected: ":1000/json",
+		},
+		{
+			name:   "registered-typed",
+			values: map[string]string{"URL": "${TIMEOUT} ${HOSTS}", "TIMEOUT": "90s", "HOSTS": "a, b"},
+			setup: func(c Config) error {
+				if err := c.NewDuration("TIMEOUT", time.Second, "timeout"); err != nil {
+					return err
+				}
+				return c.NewStringList("HOSTS", nil, "hosts")
+			},
+			expected: "1m30s a,b",
+		},
+		{
+			name:   "registered-secret",
+			values: map[string]string{"URL": "postgres://app:${DB_PASSWORD}@db/app", "DB_PASSWORD": "hunter2"},
+			setup: func(c Config) error {
+				return c.NewString("DB_PASSWORD", "", "password", WithSecret())
+			},
+			err: status.ErrInvalidArgument,
+		},
+		{
+			name:   "registered-without-expansion",
+			values: map[string]string{"URL": "${TEMPLATE}", "TEMPLATE": "${NAME}"},
+			setup: func(c Config) error {
+				return c.NewString("TEMPLATE", "", "template")
+			},
+			expected: "${NAME}",
+		},
+		{
+			name:   "registered-with-expansion",
+			values: map[string]string{"URL": "${BASE}/api", "BASE": "https://${HOST}", "HOST": "example.com"},
+			setup: func(c Config) error {
+				return c.NewString("BASE", "", "base", WithExpansion())
+			},
+			expected: "https://example.com/api",
+		},
+		{
+			name:     "nested",
+			values:   map[string]string{"URL": "${A}", "A": "a${B}", "B": "b${C}", "C": "c"},
+			expected: "abc",
+		},
+		{
+			name:     "literal",
+			values:   map[string]string{"URL": "$$HOME $HOME $${HOME} cost$"},
+			expected: "$HOME $HOME ${HOME} cost$",
+		},
+		{
+			name:   "unresolved",
+			values: map[string]string{"URL": "${MISSING}"},
+			err:    status.ErrNotFound,
+		},
+		{
+			name:   "cycle",
+			values: map[string]string{"URL": "${A}", "A": "${B}", "B": "${URL}"},
+			err:    status.ErrInvalidArgument,
+		},
+		{
+			name:   "self",
+			values: map[string]string{"URL": "${URL}"},
+			err:    status.ErrInvalidArgument,
+		},
+		{
+			name:   "unterminated",
+			values: map[string]string{"URL": "${HOST"},
+			err:    status.ErrInvalidArgument,
+		},
+		{
+			name:   "empty",
+			values: map[string]string{"URL": "${}"},
+			err:    status.ErrInvalidArgument,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			src := newTestSource()
+			for name, value := range tc.values {
+				src.SetValue(name, value)
+			}
+			c := New(WithSource(src))
+			if tc.setup != nil {
+				if err := tc.setup(c); err != nil {
+					t.Fatalf("setup() = %v; want nil", err)
+				}
+			}
+			err := c.NewString("URL", tc.defaultValue, "url", WithExpansion())
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("NewString() = %v; want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewString() = %v; want nil", err)
+			}
+			if v := c.String("URL"); v != tc.expected {
+				t.Errorf("String() = %q; want %q", v, tc.expected)
+			}
+		})
+	}
+}
+
+// Test_Expansion_CycleError tests that a cycle error names the variables in
+// the cycle.
+func Test_Expansion_CycleError(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("A", "${B}")
+	src.SetValue("B", "${A}")
+	err := New(WithSource(src)).NewString("A", "", "a", WithExpansion())
+	if err == nil || !strings.Contains(err.Error(), "A -> B -> A") {
+		t.Errorf("NewString() = %v; want cycle A -> B -> A", err)
+	}
+}
+
+// Test_Expansion_LoadPrefix tests that references are loaded with the load
+// prefix.
+func Test_Expansion_LoadPrefix(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("APP_URL", "http://${HOST}")
+	src.SetValue("APP_HOST", "example.com")
+	c := New(WithSource(src), WithLoadPrefix("APP_"))
+	if err := c.NewString("URL", "", "url", WithExpansion()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("URL"); v != "http://example.com" {
+		t.Errorf("String() = %q; want http://example.com", v)
+	}
+}
+
+// Test_Expansion_Validation tests that the expanded value is validated.
+func Test_Expansion_Validation(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("DEFAULT_MODE", "Replica")
+	c := New(WithSource(src))
+	if err := c.NewString("MODE", "${DEFAULT_MODE}", "mode", WithExpansion(), WithAllowedValues("primary", "replica"), WithCaseInsensitiveValues()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("MODE"); v != "replica" {
+		t.Errorf("String() = %q; want replica", v)
+	}
+	src.SetValue("DEFAULT_MODE", "standby")
+	if err := c.Reload(); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Reload() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_Expansion_Reload tests that references are expanded again on reload.
+func Test_Expansion_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("DB_HOST", "db1")
+	c := New(WithSource(src))
+	if err := c.NewString("DB_HOST", "", "host"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewString("URL", "postgres://${DB_HOST}/app", "url", WithExpansion()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	src.SetValue("DB_HOST", "db2")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.String("URL"); v != "postgres://db2/app" {
+		t.Errorf("String() = %q; want postgres://db2/app", v)
+	}
+}
+
+// Test_Expansion_CollectErrors tests that unresolved references are collected
+// as registration errors.
+func Test_Expansion_CollectErrors(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newTestSource()), WithCollectErrors())
+	if err := c.NewString("URL", "http://${HOST}", "url", WithExpansion()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	err := c.Err()
+	if !errors.Is(err, status.ErrNotFound) || !strings.Contains(err.Error(), "URL: ") || !strings.Contains(err.Error(), "${HOST}") {
+		t.Errorf("Err() = %v; want unresolved reference ${HOST} for URL", err)
+	}
+}
+
+// Test_Expansion_Secret tests that a secret can be referenced from a secret,
+// whose value is redacted.
+func Test_Expansion_Secret(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("DSN", "postgres://u:${PW}@h/db")
+	src.SetValue("PW", "hunter2")
+	c := New(WithSource(src))
+	if err := c.NewString("PW", "", "password", WithSecret()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewString("DSN", "", "dsn", WithSecret(), WithExpansion()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("DSN"); v != "postgres://u:hunter2@h/db" {
+		t.Errorf("String() = %q; want %q", v, "postgres://u:hunter2@h/db")
+	}
+	for name, v := range c.Dump() {
+		if v != Redacted {
+			t.Errorf("Dump()[%s] = %q; want %q", name, v, Redacted)
+		}
+	}
+	for _, info := range c.Variables() {
+		if strings.Contains(info.Value, "hunter2") {
+			t.Errorf("Variables() = %+v; want no secret", info)
+		}
+	}
+}
@@ -0,0 +1,505 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/neuralnorthwest/mu/status"
+	"gopkg.in/yaml.v3"
+)
+
+// FileFormat is the format of a configuration file.
+type FileFormat int
+
+const (
+	// FormatAuto detects the format from the file extension.
+	FormatAuto FileFormat = iota
+	// FormatYAML is the YAML format.
+	FormatYAML
+	// FormatJSON is the JSON format.
+	FormatJSON
+	// FormatTOML is the TOML format.
+	FormatTOML
+)
+
+// fileSource is a source that reads from a YAML, JSON or TOML file.
+type fileSource struct {
+	// path is the path of the file.
+	path string
+	// format is the format of the file.
+	format FileFormat
+	// prefix is the prefix for the names of the variables.
+	prefix string
+	// pollInterval is the interval at which Watch checks the file for changes.
+	pollInterval time.Duration
+	// lock guards values.
+	lock sync.RWMutex
+	// values holds the values read from the file, keyed by normalized name.
+	values map[string]interface{}
+}
+
+var _ Source = (*fileSource)(nil)
+var _ Reloader = (*fileSource)(nil)
+var _ Stager = (*fileSource)(nil)
+var _ Watcher = (*fileSource)(nil)
+
+// FileSourceOption is an option for a file source.
+type FileSourceOption func(*fileSource) error
+
+// WithFileFormat returns a FileSourceOption that sets the format of the file.
+// By default, the format is detected from the file extension.
+func WithFileFormat(format FileFormat) FileSourceOption {
+	return func(s *fileSource) error {
+		s.format = format
+		return nil
+	}
+}
+
+// WithPollInterval returns a FileSourceOption that sets the interval at which
+// Watch checks the file for changes. The default is 5 seconds.
+func WithPollInterval(interval time.Duration) FileSourceOption {
+	return func(s *fileSource) error {
+		if interval <= 0 {
+			return status.ErrOutOfRange
+		}
+		s.pollInterval = interval
+		return nil
+	}
+}
+
+// NewFileSource creates a Source that reads configuration variables from a
+// YAML, JSON or TOML file. The file is read when the source is created, and
+// again on each Reload. The source implements Reloader and Watcher, so a
+// Config using it can pick up changes to the file with Config.Reload or
+// Config.Watch.
+//
+// The document may use flat keys (DATABASE_URL: ...) or nested keys
+// (database: {url: ...}). Nested keys are joined with underscores, and all
+// keys are matched case-insensitively, with '.' and '-' treated as '_'. The
+// prefix set with SetPrefix is prepended to the variable name before lookup.
+func NewFileSource(path string, opts ...FileSourceOption) (Source, error) {
+	s := &fileSource{
+		path:         path,
+		pollInterval: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.format == FormatAuto {
+		format, err := detectFileFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		s.format = format
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the file again. If the file cannot be read, the previous
+// values are kept.
+func (s *fileSource) Reload() error {
+	_, commit, err := s.Stage()
+	if err != nil {
+		return err
+	}
+	commit()
+	return nil
+}
+
+// Stage reads the file again, without changing the source. It returns a
+// source holding the new values, and a function that applies them.
+func (s *fileSource) Stage() (Source, func(), error) {
+	values, err := readFile(s.path, s.format)
+	if err != nil {
+		return nil, nil, err
+	}
+	staged := &fileSource{
+		path:         s.path,
+		format:       s.format,
+		prefix:       s.prefix,
+		pollInterval: s.pollInterval,
+		values:       values,
+	}
+	return staged, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.values = values
+	}, nil
+}
+
+// Watch polls the file for changes to its size or modification time, and
+// calls notify when it changes. It blocks until ctx is canceled.
+func (s *fileSource) Watch(ctx context.Context, notify func()) error {
+	last := statFile(s.path)
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if stat := statFile(s.path); stat != last {
+				last = stat
+				notify()
+			}
+		}
+	}
+}
+
+// SetPrefix sets the prefix for the names of the variables.
+func (s *fileSource) SetPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// LoadInt loads the value of the int variable with the given name.
+func (s *fileSource) LoadInt(name string) (int, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	if str, ok := v.(string); ok {
+		return strconv.Atoi(str)
+	}
+	if i, ok := asInt64(v); ok {
+		return int(i), nil
+	}
+	return 0, fmt.Errorf("%w: %s: cannot use %v as int", status.ErrInvalidArgument, name, v)
+}
+
+// LoadString loads the value of the string variable with the given name.
+func (s *fileSource) LoadString(name string) (string, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return "", status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case nestedMap, []interface{}:
+		return "", fmt.Errorf("%w: %s: cannot use %v as string", status.ErrInvalidArgument, name, v)
+	}
+	return fmt.Sprint(v), nil
+}
+
+// LoadBool loads the value of the bool variable with the given name.
+func (s *fileSource) LoadBool(name string) (bool, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return false, status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, fmt.Errorf("%w: %s: cannot use %v as bool", status.ErrInvalidArgument, name, v)
+}
+
+// LoadDuration loads the value of the duration variable with the given name.
+// Durations must be strings such as "1m30s".
+func (s *fileSource) LoadDuration(name string) (time.Duration, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	if str, ok := v.(string); ok {
+		return time.ParseDuration(str)
+	}
+	return 0, fmt.Errorf("%w: %s: cannot use %v as duration", status.ErrInvalidArgument, name, v)
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (s *fileSource) LoadFloat(name string) (float64, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return parseFloat(v)
+	case json.Number:
+		return parseFloat(v.String())
+	case float64:
+		if !math.IsNaN(v) {
+			return v, nil
+		}
+	}
+	if i, ok := asInt64(v); ok {
+		return float64(i), nil
+	}
+	return 0, fmt.Errorf("%w: %s: cannot use %v as float", status.ErrInvalidArgument, name, v)
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+// Numbers are sizes in bytes; strings are parsed with ParseByteSize.
+func (s *fileSource) LoadByteSize(name string) (int64, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	if str, ok := v.(string); ok {
+		return ParseByteSize(str)
+	}
+	if i, ok := asInt64(v); ok {
+		return i, nil
+	}
+	return 0, fmt.Errorf("%w: %s: cannot use %v as byte size", status.ErrInvalidArgument, name, v)
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name. The value may be a list of scalars, or a string split on sep.
+func (s *fileSource) LoadStringList(name string, sep string) ([]string, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return SplitList(v, sep), nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, e := range v {
+			str, ok := asScalarString(e)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: cannot use %v as string", status.ErrInvalidArgument, name, e)
+			}
+			list[i] = str
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("%w: %s: cannot use %v as string list", status.ErrInvalidArgument, name, v)
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+// The value may be a list of ints, or a string split on sep.
+func (s *fileSource) LoadIntList(name string, sep string) ([]int, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return ParseIntList(v, sep)
+	case []interface{}:
+		list := make([]int, len(v))
+		for i, e := range v {
+			if str, ok := e.(string); ok {
+				n, err := strconv.Atoi(strings.TrimSpace(str))
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s: cannot use %q as int", status.ErrInvalidArgument, name, str)
+				}
+				list[i] = n
+				continue
+			}
+			n, ok := asInt64(e)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: cannot use %v as int", status.ErrInvalidArgument, name, e)
+			}
+			list[i] = int(n)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("%w: %s: cannot use %v as int list", status.ErrInvalidArgument, name, v)
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name. The value may be a table of scalars, or a string split on sep and
+// kvSep. The keys of a table keep their case.
+func (s *fileSource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return ParseStringMap(v, sep, kvSep)
+	case nestedMap:
+		m := make(map[string]string, len(v))
+		for k, e := range v {
+			str, ok := asScalarString(e)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: cannot use %v as string", status.ErrInvalidArgument, name, e)
+			}
+			m[k] = str
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("%w: %s: cannot use %v as string map", status.ErrInvalidArgument, name, v)
+}
+
+// lookup returns the value of the variable with the given name. A null value
+// (KEY: or KEY: ~ in YAML, null in JSON) is treated as not set, so that the
+// default applies.
+func (s *fileSource) lookup(name string) (interface{}, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, ok := s.values[normalizeKey(s.prefix+name)]
+	return v, ok && v != nil
+}
+
+// asScalarString returns v as a string, if it is a scalar.
+func asScalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case nil, nestedMap, []interface{}, map[string]interface{}, map[interface{}]interface{}:
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+// asInt64 returns v as an int64, if it is an integral number.
+func asInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// fileStat holds the properties of a file that Watch compares.
+type fileStat struct {
+	// size is the size of the file.
+	size int64
+	// modTime is the modification time of the file, in nanoseconds.
+	modTime int64
+}
+
+// statFile returns the fileStat of the file at path, or the zero fileStat if
+// the file cannot be read.
+func statFile(path string) fileStat {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
+
+// detectFileFormat detects the format of a file from its extension.
+func detectFileFormat(path string) (FileFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return FormatAuto, fmt.Errorf("%w: unknown config file extension: %s", status.ErrInvalidArgument, path)
+}
+
+// readFile reads and flattens the configuration file at path.
+func readFile(path string, format FileFormat) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &doc)
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &doc)
+	default:
+		err = fmt.Errorf("%w: unknown config file format: %d", status.ErrInvalidArgument, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	values := make(map[string]interface{})
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// nestedMap is a table of a configuration file. Tables are flattened into
+// values, and are also kept whole so that they can be loaded as string maps.
+type nestedMap map[string]interface{}
+
+// flatten flattens a nested document into values, joining nested keys with
+// underscores.
+func flatten(prefix string, doc map[string]interface{}, values map[string]interface{}) error {
+	for k, v := range doc {
+		key := prefix + k
+		if m, ok := asMap(v); ok {
+			if err := flatten(key+"_", m, values); err != nil {
+				return err
+			}
+			v = nestedMap(m)
+		}
+		norm := normalizeKey(key)
+		if _, ok := values[norm]; ok {
+			return fmt.Errorf("%w: %s", status.ErrAlreadyExists, key)
+		}
+		values[norm] = v
+	}
+	return nil
+}
+
+// asMap returns v as a map with string keys, if it is a map.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = e
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// normalizeKey normalizes a key for case-insensitive lookup.
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
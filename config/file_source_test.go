@@ -0,0 +1,387 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// writeConfigFile writes a configuration file to a temporary directory and
+// returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	return path
+}
+
+// Test_FileSource_Formats_Case is a test case for Test_FileSource_Formats.
+type Test_FileSource_Formats_Case struct {
+	// name is the name of the test case.
+	name string
+	// fileName is the name of the configuration file.
+	fileName string
+	// content is the content of the configuration file.
+	content string
+}
+
+// Test_FileSource_Formats tests that the file source loads flat and nested
+// keys from each supported format.
+func Test_FileSource_Formats(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_FileSource_Formats_Case{
+		{
+			name:     "yaml",
+			fileName: "config.yaml",
+			content: `
+PORT: 8080
+MESSAGE: hello
+DEBUG: true
+database:
+  url: postgres://localhost/app
+  pool-size: 4
+`,
+		},
+		{
+			name:     "yml",
+			fileName: "config.yml",
+			content: `
+port: 8080
+message: hello
+debug: true
+database.url: postgres://localhost/app
+database:
+  pool_size: 4
+`,
+		},
+		{
+			name:     "json",
+			fileName: "config.json",
+			content: `{
+	"PORT": 8080,
+	"MESSAGE": "hello",
+	"DEBUG": true,
+	"database": {"url": "postgres://localhost/app", "pool-size": 4}
+}`,
+		},
+		{
+			name:     "toml",
+			fileName: "config.toml",
+			content: `
+PORT = 8080
+MESSAGE = "hello"
+DEBUG = true
+
+[database]
+url = "postgres://localhost/app"
+pool-size = 4
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			src, err := NewFileSource(writeConfigFile(t, tc.fileName, tc.content))
+			if err != nil {
+				t.Fatalf("NewFileSource() = %v; want nil", err)
+			}
+			c := New(WithSource(src))
+			if err := c.NewInt("PORT", 80, "port"); err != nil {
+				t.Fatalf("NewInt() = %v; want nil", err)
+			}
+			if err := c.NewString("MESSAGE", "", "message"); err != nil {
+				t.Fatalf("NewString() = %v; want nil", err)
+			}
+			if err := c.NewBool("DEBUG", false, "debug"); err != nil {
+				t.Fatalf("NewBool() = %v; want nil", err)
+			}
+			if err := c.NewString("DATABASE_URL", "", "database url"); err != nil {
+				t.Fatalf("NewString() = %v; want nil", err)
+			}
+			if err := c.NewInt("DATABASE_POOL_SIZE", 1, "pool size"); err != nil {
+				t.Fatalf("NewInt() = %v; want nil", err)
+			}
+			if err := c.NewString("MISSING", "default", "missing"); err != nil {
+				t.Fatalf("NewString() = %v; want nil", err)
+			}
+			if v := c.Int("PORT"); v != 8080 {
+				t.Errorf("Int() = %v; want 8080", v)
+			}
+			if v := c.String("MESSAGE"); v != "hello" {
+				t.Errorf("String() = %v; want hello", v)
+			}
+			if v := c.Bool("DEBUG"); !v {
+				t.Errorf("Bool() = %v; want true", v)
+			}
+			if v := c.String("DATABASE_URL"); v != "postgres://localhost/app" {
+				t.Errorf("String() = %v; want postgres://localhost/app", v)
+			}
+			if v := c.Int("DATABASE_POOL_SIZE"); v != 4 {
+				t.Errorf("Int() = %v; want 4", v)
+			}
+			if v := c.String("MISSING"); v != "default" {
+				t.Errorf("String() = %v; want default", v)
+			}
+		})
+	}
+}
+
+// Test_FileSource_LoadPrefix tests that the file source honours the load
+// prefix.
+func Test_FileSource_LoadPrefix(t *testing.T) {
+	t.Parallel()
+	src, err := NewFileSource(writeConfigFile(t, "config.yaml", "myapp:\n  port: 9090\nport: 80\n"))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src), WithLoadPrefix("MYAPP_"))
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+}
+
+// Test_FileSource_Conversions tests conversions between file value types and
+// variable types.
+func Test_FileSource_Conversions(t *testing.T) {
+	t.Parallel()
+	src, err := NewFileSource(writeConfigFile(t, "config.yaml", `
+int_string: "42"
+int_float: 3.0
+bad_float: 3.5
+bool_string: "true"
+number: 7
+list: [1, 2]
+`))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	if v, err := src.LoadInt("INT_STRING"); err != nil || v != 42 {
+		t.Errorf("LoadInt() = %v, %v; want 42, nil", v, err)
+	}
+	if v, err := src.LoadInt("INT_FLOAT"); err != nil || v != 3 {
+		t.Errorf("LoadInt() = %v, %v; want 3, nil", v, err)
+	}
+	if _, err := src.LoadInt("BAD_FLOAT"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadInt() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v, err := src.LoadBool("BOOL_STRING"); err != nil || !v {
+		t.Errorf("LoadBool() = %v, %v; want true, nil", v, err)
+	}
+	if _, err := src.LoadBool("NUMBER"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadBool() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v, err := src.LoadString("NUMBER"); err != nil || v != "7" {
+		t.Errorf("LoadString() = %v, %v; want 7, nil", v, err)
+	}
+	if _, err := src.LoadString("LIST"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadString() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if _, err := src.LoadString("UNKNOWN"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadString() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadInt("UNKNOWN"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadInt() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadBool("UNKNOWN"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadBool() = %v; want %v", err, status.ErrNotFound)
+	}
+}
+
+// Test_FileSource_Null tests that null values are treated as not set, so
+// that the defaults of the variables apply.
+func Test_FileSource_Null(t *testing.T) {
+	t.Parallel()
+	src, err := NewFileSource(writeConfigFile(t, "config.yaml", `
+message:
+port: ~
+debug: null
+`))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	if _, err := src.LoadString("MESSAGE"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadString() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadInt("PORT"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadInt() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadBool("DEBUG"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadBool() = %v; want %v", err, status.ErrNotFound)
+	}
+	c := New(WithSource(src))
+	if err := c.NewString("MESSAGE", "hello", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := c.String("MESSAGE"); v != "hello" {
+		t.Errorf("String() = %q; want %q", v, "hello")
+	}
+	if v := c.Int("PORT"); v != 8080 {
+		t.Errorf("Int() = %v; want 8080", v)
+	}
+}
+
+// Test_FileSource_Numbers tests loading durations, floats, and byte sizes
+// from a file.
+func Test_FileSource_Numbers(t *testing.T) {
+	t.Parallel()
+	src, err := NewFileSource(writeConfigFile(t, "config.json", `{
+	"timeout": "1m30s",
+	"bad_timeout": 90,
+	"ratio": 0.25,
+	"int_ratio": 2,
+	"string_ratio": "0.5",
+	"size": "512Mi",
+	"int_size": 4096,
+	"bad_size": true
+}`))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	if v, err := src.LoadDuration("TIMEOUT"); err != nil || v != 90*time.Second {
+		t.Errorf("LoadDuration() = %v, %v; want 1m30s, nil", v, err)
+	}
+	if _, err := src.LoadDuration("BAD_TIMEOUT"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadDuration() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v, err := src.LoadFloat("RATIO"); err != nil || v != 0.25 {
+		t.Errorf("LoadFloat() = %v, %v; want 0.25, nil", v, err)
+	}
+	if v, err := src.LoadFloat("INT_RATIO"); err != nil || v != 2 {
+		t.Errorf("LoadFloat() = %v, %v; want 2, nil", v, err)
+	}
+	if v, err := src.LoadFloat("STRING_RATIO"); err != nil || v != 0.5 {
+		t.Errorf("LoadFloat() = %v, %v; want 0.5, nil", v, err)
+	}
+	if v, err := src.LoadByteSize("SIZE"); err != nil || v != 512<<20 {
+		t.Errorf("LoadByteSize() = %v, %v; want %v, nil", v, err, 512<<20)
+	}
+	if v, err := src.LoadByteSize("INT_SIZE"); err != nil || v != 4096 {
+		t.Errorf("LoadByteSize() = %v, %v; want 4096, nil", v, err)
+	}
+	if _, err := src.LoadByteSize("BAD_SIZE"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadByteSize() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	for _, load := range []func(string) error{
+		func(name string) error { _, err := src.LoadDuration(name); return err },
+		func(name string) error { _, err := src.LoadFloat(name); return err },
+		func(name string) error { _, err := src.LoadByteSize(name); return err },
+	} {
+		if err := load("UNKNOWN"); !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("load() = %v; want %v", err, status.ErrNotFound)
+		}
+	}
+}
+
+// Test_FileSource_Collections tests loading lists and maps from a file.
+func Test_FileSource_Collections(t *testing.T) {
+	t.Parallel()
+	src, err := NewFileSource(writeConfigFile(t, "config.yaml", `
+origins: [http://a, http://b]
+origins_text: "http://a; http://b"
+ports: [80, "443"]
+ports_text: 80,443
+bad_ports: [80, x]
+labels:
+  Team: core
+  tier: 1
+labels_text: team=core
+bad_labels:
+  nested: {a: b}
+`))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	want := []string{"http://a", "http://b"}
+	if v, err := src.LoadStringList("ORIGINS", ","); err != nil || !cmp.Equal(v, want) {
+		t.Errorf("LoadStringList() = %v, %v; want %v, nil", v, err, want)
+	}
+	if v, err := src.LoadStringList("ORIGINS_TEXT", ";"); err != nil || !cmp.Equal(v, want) {
+		t.Errorf("LoadStringList() = %v, %v; want %v, nil", v, err, want)
+	}
+	if _, err := src.LoadStringList("LABELS", ","); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadStringList() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	for _, name := range []string{"PORTS", "PORTS_TEXT"} {
+		if v, err := src.LoadIntList(name, ","); err != nil || !cmp.Equal(v, []int{80, 443}) {
+			t.Errorf("LoadIntList(%q) = %v, %v; want [80 443], nil", name, v, err)
+		}
+	}
+	if _, err := src.LoadIntList("BAD_PORTS", ","); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadIntList() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v, err := src.LoadStringMap("LABELS", ",", "="); err != nil || !cmp.Equal(v, map[string]string{"Team": "core", "tier": "1"}) {
+		t.Errorf("LoadStringMap() = %v, %v; want map[Team:core tier:1], nil", v, err)
+	}
+	if v, err := src.LoadStringMap("LABELS_TEXT", ",", "="); err != nil || !cmp.Equal(v, map[string]string{"team": "core"}) {
+		t.Errorf("LoadStringMap() = %v, %v; want map[team:core], nil", v, err)
+	}
+	if _, err := src.LoadStringMap("BAD_LABELS", ",", "="); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadStringMap() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v, err := src.LoadString("LABELS_TEAM"); err != nil || v != "core" {
+		t.Errorf("LoadString() = %v, %v; want core, nil", v, err)
+	}
+	if _, err := src.LoadString("LABELS"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("LoadString() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_FileSource_WithFileFormat tests that WithFileFormat overrides format
+// detection.
+func Test_FileSource_WithFileFormat(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "config", `{"PORT": 9090}`)
+	if _, err := NewFileSource(path); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewFileSource() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	src, err := NewFileSource(path, WithFileFormat(FormatJSON))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	if v, err := src.LoadInt("PORT"); err != nil || v != 9090 {
+		t.Errorf("LoadInt() = %v, %v; want 9090, nil", v, err)
+	}
+}
+
+// Test_FileSource_Errors tests that NewFileSource returns an error for
+// missing, malformed, and ambiguous files.
+func Test_FileSource_Errors(t *testing.T) {
+	t.Parallel()
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewFileSource() = %v; want %v", err, os.ErrNotExist)
+	}
+	if _, err := NewFileSource(writeConfigFile(t, "bad.json", "{")); err == nil {
+		t.Errorf("NewFileSource() = nil; want error")
+	}
+	path := writeConfigFile(t, "dup.yaml", "database_url: a\ndatabase:\n  url: b\n")
+	if _, err := NewFileSource(path); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewFileSource() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// flagSource is a source that reads from the flags of a pflag.FlagSet.
+type flagSource struct {
+	textLoader
+	// flags is the flag set.
+	flags *pflag.FlagSet
+}
+
+// NewFlagSource creates a Source that reads configuration variables from the
+// flags of a pflag.FlagSet. The value of the variable with the given name is
+// read from the flag named by FlagName, and only when the flag was set on the
+// command line; a flag left at its default is treated as not set, so that
+// lower-precedence sources still apply. Flag values are parsed from their
+// text in the same way as environment variables.
+func NewFlagSource(flags *pflag.FlagSet) Source {
+	s := &flagSource{flags: flags}
+	s.textLoader = func(name string) (string, bool) {
+		f := s.flags.Lookup(FlagName(name))
+		if f == nil || !f.Changed {
+			return "", false
+		}
+		return f.Value.String(), true
+	}
+	return s
+}
+
+// SetPrefix does nothing. The load prefix names environment variables, and is
+// not part of flag names.
+func (s *flagSource) SetPrefix(prefix string) {}
+
+// FlagName returns the name of the flag for the variable with the given name.
+// The name is lower-cased and underscores are replaced with dashes, so that
+// DEV_MODE becomes dev-mode.
+func FlagName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// AddFlags defines a flag on flags for each of the given variables, named by
+// FlagName and described by the variable's description. Bool variables get
+// bool flags, which may be given without a value; every other variable gets a
+// string flag holding the variable's text. Variables whose flag is already
+// defined are skipped, as are secrets, which would otherwise be visible in
+// process listings and shell history; set them in the environment or from a
+// NAME_FILE file instead. Use AddFlags with Config.Variables and NewFlagSource
+// to set configuration variables from the command line.
+func AddFlags(flags *pflag.FlagSet, vars []VariableInfo) {
+	for _, v := range vars {
+		name := FlagName(v.Name)
+		if v.Secret || flags.Lookup(name) != nil {
+			continue
+		}
+		if v.Type == TypeBool {
+			def, _ := v.defaultValue.(bool)
+			flags.Bool(name, def, v.Description)
+			continue
+		}
+		flags.String(name, v.Default, v.Description)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+	"github.com/spf13/pflag"
+)
+
+// Test_FlagName tests the FlagName function.
+func Test_FlagName(t *testing.T) {
+	t.Parallel()
+	for name, want := range map[string]string{
+		"MESSAGE":       "message",
+		"DEV_MODE":      "dev-mode",
+		"HTTP_MAX_CONN": "http-max-conn",
+	} {
+		if v := FlagName(name); v != want {
+			t.Errorf("FlagName(%q) = %q; want %q", name, v, want)
+		}
+	}
+}
+
+// newFlagTestConfig returns a config with one variable of each type and a
+// secret, and a flag set with a flag for each of them but the secret.
+func newFlagTestConfig(t *testing.T) (Config, *pflag.FlagSet) {
+	t.Helper()
+	c := New(WithSource(NewChainSource()))
+	for _, err := range []error{
+		c.NewInt("PORT", 8080, "port"),
+		c.NewString("MESSAGE", "hello", "message"),
+		c.NewBool("DEV_MODE", false, "development mode"),
+		c.NewDuration("TIMEOUT", time.Second, "timeout"),
+		c.NewFloat("RATIO", 0.5, "ratio"),
+		c.NewByteSize("MAX_BODY", 1024, "maximum body size"),
+		c.NewStringList("HOSTS", nil, "hosts"),
+		c.NewIntList("PORTS", nil, "ports"),
+		c.NewStringMap("LABELS", nil, "labels"),
+		c.NewString("PASSWORD", "secret", "password", WithSecret()),
+	} {
+		if err != nil {
+			t.Fatalf("New() = %v; want nil", err)
+		}
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(fs, c.Variables())
+	return c, fs
+}
+
+// Test_AddFlags tests that AddFlags defines a flag for each variable.
+func Test_AddFlags(t *testing.T) {
+	t.Parallel()
+	_, fs := newFlagTestConfig(t)
+	for name, want := range map[string]string{
+		"port":     "string",
+		"message":  "string",
+		"dev-mode": "bool",
+		"timeout":  "string",
+		"ratio":    "string",
+		"max-body": "string",
+		"hosts":    "string",
+		"ports":    "string",
+		"labels":   "string",
+	} {
+		f := fs.Lookup(name)
+		if f == nil {
+			t.Errorf("Lookup(%q) = nil; want flag", name)
+			continue
+		}
+		if v := f.Value.Type(); v != want {
+			t.Errorf("Lookup(%q).Value.Type() = %q; want %q", name, v, want)
+		}
+	}
+	if v := fs.Lookup("message"); v.DefValue != "hello" || v.Usage != "message" {
+		t.Errorf("Lookup(message) = %q, %q; want hello, message", v.DefValue, v.Usage)
+	}
+	if f := fs.Lookup("password"); f != nil {
+		t.Errorf("Lookup(password) = %v; want nil", f)
+	}
+}
+
+// Test_AddFlags_Existing tests that AddFlags skips flags that are already
+// defined.
+func Test_AddFlags_Existing(t *testing.T) {
+	t.Parallel()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("port", 1, "existing")
+	AddFlags(fs, []VariableInfo{{Name: "PORT", Type: TypeInt, Description: "port"}})
+	if v := fs.Lookup("port").Usage; v != "existing" {
+		t.Errorf("Lookup(port).Usage = %q; want existing", v)
+	}
+}
+
+// Test_FlagSource tests loading variables from flags.
+func Test_FlagSource(t *testing.T) {
+	t.Parallel()
+	_, fs := newFlagTestConfig(t)
+	if err := fs.Parse([]string{
+		"--port", "9090",
+		"--message=from flags",
+		"--dev-mode",
+		"--timeout", "5s",
+		"--ratio", "0.25",
+		"--max-body", "2KiB",
+		"--hosts", "a, b",
+		"--ports", "1,2",
+		"--labels", "env=dev",
+	}); err != nil {
+		t.Fatalf("Parse() = %v; want nil", err)
+	}
+	c := New(WithSource(NewFlagSource(fs)))
+	for _, err := range []error{
+		c.NewInt("PORT", 8080, "port"),
+		c.NewString("MESSAGE", "hello", "message"),
+		c.NewBool("DEV_MODE", false, "development mode"),
+		c.NewDuration("TIMEOUT", time.Second, "timeout"),
+		c.NewFloat("RATIO", 0.5, "ratio"),
+		c.NewByteSize("MAX_BODY", 1024, "maximum body size"),
+		c.NewStringList("HOSTS", nil, "hosts"),
+		c.NewIntList("PORTS", nil, "ports"),
+		c.NewStringMap("LABELS", nil, "labels"),
+		c.NewString("PASSWORD", "secret", "password", WithSecret()),
+	} {
+		if err != nil {
+			t.Fatalf("New() = %v; want nil", err)
+		}
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+	if v := c.String("MESSAGE"); v != "from flags" {
+		t.Errorf("String() = %v; want from flags", v)
+	}
+	if v := c.Bool("DEV_MODE"); !v {
+		t.Errorf("Bool() = %v; want true", v)
+	}
+	if v := c.Duration("TIMEOUT"); v != 5*time.Second {
+		t.Errorf("Duration() = %v; want 5s", v)
+	}
+	if v := c.Float("RATIO"); v != 0.25 {
+		t.Errorf("Float() = %v; want 0.25", v)
+	}
+	if v := c.ByteSize("MAX_BODY"); v != 2048 {
+		t.Errorf("ByteSize() = %v; want 2048", v)
+	}
+	if v := c.StringList("HOSTS"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("StringList() = %v; want [a b]", v)
+	}
+	if v := c.IntList("PORTS"); len(v) != 2 || v[0] != 1 || v[1] != 2 {
+		t.Errorf("IntList() = %v; want [1 2]", v)
+	}
+	if v := c.StringMap("LABELS"); len(v) != 1 || v["env"] != "dev" {
+		t.Errorf("StringMap() = %v; want map[env:dev]", v)
+	}
+	if v := c.String("PASSWORD"); v != "secret" {
+		t.Errorf("String() = %v; want secret", v)
+	}
+}
+
+// Test_FlagSource_NotSet tests that flags that are not set, or not defined,
+// are not found.
+func Test_FlagSource_NotSet(t *testing.T) {
+	t.Parallel()
+	_, fs := newFlagTestConfig(t)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse() = %v; want nil", err)
+	}
+	src := NewFlagSource(fs)
+	if _, err := src.LoadString("MESSAGE"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadString() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadBool("DEV_MODE"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadBool() = %v; want %v", err, status.ErrNotFound)
+	}
+	if _, err := src.LoadInt("UNDEFINED"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("LoadInt() = %v; want %v", err, status.ErrNotFound)
+	}
+}
+
+// Test_FlagSource_Precedence tests layering flags over the environment.
+func Test_FlagSource_Precedence(t *testing.T) {
+	t.Setenv("APP_MESSAGE", "env")
+	t.Setenv("APP_PORT", "1")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(fs, []VariableInfo{
+		{Name: "MESSAGE", Type: TypeString},
+		{Name: "PORT", Type: TypeInt},
+	})
+	if err := fs.Parse([]string{"--message", "flags"}); err != nil {
+		t.Fatalf("Parse() = %v; want nil", err)
+	}
+	chain := NewChainSource(
+		Layer{Name: "flags", Source: NewFlagSource(fs)},
+		Layer{Name: "env", Source: NewEnvSource()},
+	)
+	c := New(WithSource(chain), WithLoadPrefix("APP_"))
+	if err := c.NewString("MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := c.String("MESSAGE"); v != "flags" {
+		t.Errorf("String() = %v; want flags", v)
+	}
+	if v := c.Int("PORT"); v != 1 {
+		t.Errorf("Int() = %v; want 1", v)
+	}
+	if v := variableOrigin(c, "MESSAGE"); v != "flags" {
+		t.Errorf("Origin = %q; want flags", v)
+	}
+}
+
+// Test_FlagSource_Invalid tests that an invalid flag value is an error.
+func Test_FlagSource_Invalid(t *testing.T) {
+	t.Parallel()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(fs, []VariableInfo{{Name: "PORT", Type: TypeInt}})
+	if err := fs.Parse([]string{"--port", "invalid"}); err != nil {
+		t.Fatalf("Parse() = %v; want nil", err)
+	}
+	if _, err := NewFlagSource(fs).LoadInt("PORT"); err == nil {
+		t.Errorf("LoadInt() = nil; want error")
+	}
+}
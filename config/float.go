@@ -0,0 +1,224 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Float is a configuration value that is a float.
+type Float struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value float64
+	// defaultValue is the default value of the variable.
+	defaultValue float64
+	// description is the description of the variable.
+	description string
+	// minimumValue is the minimum value of the variable.
+	minimumValue float64
+	// maximumValue is the maximum value of the variable.
+	maximumValue float64
+	// clampValue is true if the value should be clamped to the minimum and maximum values.
+	clampValue bool
+	// validator is the validator for the variable.
+	validator func(float64) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// FloatOption is an option for a float variable.
+type FloatOption interface {
+	// applyFloat applies the option to a float variable.
+	applyFloat(c *configImpl, f *Float) error
+}
+
+// floatOption is a FloatOption implemented by a function.
+type floatOption func(*configImpl, *Float) error
+
+// applyFloat implements FloatOption.
+func (o floatOption) applyFloat(c *configImpl, f *Float) error {
+	return o(c, f)
+}
+
+// WithMinimumFloat returns an option that sets the minimum value for a float variable.
+func WithMinimumFloat(min float64) FloatOption {
+	return floatOption(func(c *configImpl, f *Float) error {
+		f.minimumValue = min
+		return nil
+	})
+}
+
+// WithMaximumFloat returns an option that sets the maximum value for a float variable.
+func WithMaximumFloat(max float64) FloatOption {
+	return floatOption(func(c *configImpl, f *Float) error {
+		f.maximumValue = max
+		return nil
+	})
+}
+
+// WithFloatValidator returns an option that sets the validator for a float variable.
+func WithFloatValidator(f func(float64) error) FloatOption {
+	return floatOption(func(c *configImpl, fl *Float) error {
+		fl.validator = f
+		return nil
+	})
+}
+
+// WithFloatClamping returns an option that enables clamping for a float variable.
+func WithFloatClamping() FloatOption {
+	return floatOption(func(c *configImpl, f *Float) error {
+		f.clampValue = true
+		return nil
+	})
+}
+
+// NewFloat creates a new float variable.
+func (c *configImpl) NewFloat(name string, defaultValue float64, description string, options ...FloatOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	f := &Float{
+		name:         name,
+		defaultValue: defaultValue,
+		description:  description,
+		minimumValue: -math.MaxFloat64,
+		maximumValue: math.MaxFloat64,
+	}
+	for _, opt := range options {
+		if err := opt.applyFloat(c, f); err != nil {
+			return err
+		}
+	}
+	if f.minimumValue > f.maximumValue {
+		return status.ErrInvalidRange
+	}
+	if math.IsNaN(f.defaultValue) {
+		return status.ErrInvalidArgument
+	}
+	var err error
+	f.defaultValue, err = clampOrError(f.defaultValue, f.minimumValue, f.maximumValue, f.clampValue)
+	if err != nil {
+		return err
+	}
+	if f.validator != nil {
+		if err := f.validator(f.defaultValue); err != nil {
+			return err
+		}
+	}
+	return c.register(name, f)
+}
+
+// load implements variable.
+func (f *Float) load(src Source) (interface{}, error) {
+	v, err := src.LoadFloat(f.name)
+	if errors.Is(err, status.ErrNotFound) {
+		if f.required {
+			return nil, errRequired
+		}
+		return f.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(v) {
+		return nil, status.ErrInvalidArgument
+	}
+	v, err = clampOrError(v, f.minimumValue, f.maximumValue, f.clampValue)
+	if err != nil {
+		return nil, err
+	}
+	if f.validator != nil {
+		if err := f.validator(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (f *Float) set(value interface{}) bool {
+	old := f.value
+	f.value = value.(float64)
+	return f.value != old
+}
+
+// format implements variable.
+func (f *Float) format() string {
+	return f.text(f.value)
+}
+
+// text implements variable.
+func (f *Float) text(value interface{}) string {
+	return strconv.FormatFloat(value.(float64), 'g', -1, 64)
+}
+
+// initialValue implements variable.
+func (f *Float) initialValue() interface{} {
+	return f.defaultValue
+}
+
+// info implements variable.
+func (f *Float) info() VariableInfo {
+	info := VariableInfo{
+		Name:         f.name,
+		Type:         TypeFloat,
+		Description:  f.description,
+		Default:      strconv.FormatFloat(f.defaultValue, 'g', -1, 64),
+		Value:        f.format(),
+		Required:     f.required,
+		Clamped:      f.clampValue,
+		defaultValue: f.defaultValue,
+	}
+	if f.minimumValue != -math.MaxFloat64 {
+		info.Minimum = strconv.FormatFloat(f.minimumValue, 'g', -1, 64)
+	}
+	if f.maximumValue != math.MaxFloat64 {
+		info.Maximum = strconv.FormatFloat(f.maximumValue, 'g', -1, 64)
+	}
+	return info
+}
+
+// Float returns the value of the float variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, Float returns 0.
+func (c *configImpl) Float(name string) float64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if f, ok := c.floats[name]; ok {
+		return f.value
+	}
+	defer bug.Bugf("config: float variable %q does not exist", name)
+	return 0
+}
+
+// DescribeFloat returns the description of the float variable with the given name. If the variable does not exist, it calls bug.Bug.
+// If bug.Bug does not panic, DescribeFloat returns "".
+func (c *configImpl) DescribeFloat(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if f, ok := c.floats[name]; ok {
+		return f.description
+	}
+	defer bug.Bugf("config: float variable %q does not exist", name)
+	return ""
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_NewFloat_Case is a test case for NewFloat.
+type Test_NewFloat_Case struct {
+	// name is the name of the test case.
+	name string
+	// defaultValue is the default value of the variable.
+	defaultValue float64
+	// options are the options for the variable.
+	options []FloatOption
+	// expected is the expected result.
+	expected *Float
+	// err is the expected error.
+	err error
+}
+
+// Test_NewFloat tests the NewFloat function.
+func Test_NewFloat(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_NewFloat_Case{
+		{
+			name:         "basic case",
+			defaultValue: 1.5,
+			expected: &Float{
+				name:         "test",
+				value:        1.5,
+				defaultValue: 1.5,
+				description:  "test",
+				minimumValue: -math.MaxFloat64,
+				maximumValue: math.MaxFloat64,
+			},
+		},
+		{
+			name:         "with minimum value, default value less than minimum value",
+			defaultValue: 1.5,
+			options:      []FloatOption{WithMinimumFloat(2.5)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with minimum value, default value less than minimum value, with clamping",
+			defaultValue: 1.5,
+			options:      []FloatOption{WithMinimumFloat(2.5), WithFloatClamping()},
+			expected: &Float{
+				name:         "test",
+				value:        2.5,
+				defaultValue: 2.5,
+				description:  "test",
+				minimumValue: 2.5,
+				maximumValue: math.MaxFloat64,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value",
+			defaultValue: 3.5,
+			options:      []FloatOption{WithMaximumFloat(2.5)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:         "with maximum value, default value greater than maximum value, with clamping",
+			defaultValue: 3.5,
+			options:      []FloatOption{WithMaximumFloat(2.5), WithFloatClamping()},
+			expected: &Float{
+				name:         "test",
+				value:        2.5,
+				defaultValue: 2.5,
+				description:  "test",
+				minimumValue: -math.MaxFloat64,
+				maximumValue: 2.5,
+				clampValue:   true,
+			},
+		},
+		{
+			name:         "with validator, default value invalid",
+			defaultValue: 1.5,
+			options: []FloatOption{WithFloatValidator(func(v float64) error {
+				return status.ErrInvalidArgument
+			})},
+			err: status.ErrInvalidArgument,
+		},
+		{
+			name:         "minimum value greater than maximum value",
+			defaultValue: 2.5,
+			options:      []FloatOption{WithMinimumFloat(3.5), WithMaximumFloat(1.5)},
+			err:          status.ErrInvalidRange,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(WithSource(newNullSource())).(*configImpl)
+			err := c.NewFloat("test", tc.defaultValue, "test", tc.options...)
+			if err != nil {
+				if tc.err == nil {
+					t.Errorf("NewFloat() = %v; want nil", err)
+				} else if !errors.Is(err, tc.err) {
+					t.Errorf("NewFloat() = %v; want %v", err, tc.err)
+				}
+				return
+			}
+			if tc.err != nil {
+				t.Errorf("NewFloat() = nil; want %v", tc.err)
+				return
+			}
+			opts := []cmp.Option{
+				cmp.AllowUnexported(Float{}, legacyNames{}),
+				cmpopts.IgnoreFields(Float{}, "validator"),
+			}
+			if diff := cmp.Diff(tc.expected, c.floats["test"], opts...); diff != "" {
+				t.Errorf("NewFloat() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_NewFloat_AlreadyExists tests that NewFloat() returns an error if the
+// variable name already exists.
+func Test_NewFloat_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewFloat("test", 1.5, "test"); err != nil {
+		t.Fatalf("NewFloat() = %v; want nil", err)
+	}
+	if err := c.NewFloat("test", 1.5, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewFloat() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_Float_FromTestSource tests that Float() returns the value set in the test
+// source, and that the value is range checked, clamped, and validated.
+func Test_Float_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", "2.5")
+	src.SetValue("clamped", "3.5")
+	src.SetValue("invalid", "-0.5")
+	src.SetValue("malformed", "abc")
+	c := New(WithSource(src))
+	if err := c.NewFloat("test", 1.5, "test"); err != nil {
+		t.Fatalf("NewFloat() = %v; want nil", err)
+	}
+	if v := c.Float("test"); v != 2.5 {
+		t.Errorf("Float() = %v; want %v", v, 2.5)
+	}
+	if err := c.NewFloat("clamped", 1.5, "test", WithMaximumFloat(2.5)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewFloat() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewFloat("clamped", 1.5, "test", WithMaximumFloat(2.5), WithFloatClamping()); err != nil {
+		t.Fatalf("NewFloat() = %v; want nil", err)
+	}
+	if v := c.Float("clamped"); v != 2.5 {
+		t.Errorf("Float() = %v; want %v", v, 2.5)
+	}
+	validator := WithFloatValidator(func(v float64) error {
+		if v < 0 {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})
+	if err := c.NewFloat("invalid", 1.5, "test", WithMinimumFloat(-0.5*2), validator); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewFloat() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewFloat("malformed", 1.5, "test"); err == nil {
+		t.Errorf("NewFloat() = nil; want error")
+	}
+}
+
+// Test_Float_FromErrorSource tests that NewFloat() returns the error returned by
+// the source.
+func Test_Float_FromErrorSource(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newErrorSource(status.ErrInvalidArgument)))
+	if err := c.NewFloat("test", 1.5, "test"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewFloat() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_Float_FromEnv tests that Float() returns the value set in the environment.
+func Test_Float_FromEnv(t *testing.T) {
+	t.Setenv("prefix-test", "2.5")
+	c := New(WithLoadPrefix("prefix-"))
+	if err := c.NewFloat("test", 1.5, "test"); err != nil {
+		t.Fatalf("NewFloat() = %v; want nil", err)
+	}
+	if v := c.Float("test"); v != 2.5 {
+		t.Errorf("Float() = %v; want %v", v, 2.5)
+	}
+}
+
+// Test_Float_Unknown tests that Float() and DescribeFloat() call bug.Bug if the
+// variable name is unknown.
+func Test_Float_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: float variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.Float("test")
+	_ = c.DescribeFloat("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeFloat tests that DescribeFloat() returns the correct value.
+func Test_DescribeFloat(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewFloat("test", 1.5, "description"); err != nil {
+		t.Fatalf("NewFloat() = %v; want nil", err)
+	}
+	if v := c.DescribeFloat("test"); v != "description" {
+		t.Errorf("DescribeFloat() = %v; want description", v)
+	}
+}
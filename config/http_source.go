@@ -0,0 +1,292 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// httpSource is a source that reads from a key-value store over HTTP.
+type httpSource struct {
+	textLoader
+	// url is the URL of the key-value API, such as
+	// http://localhost:8500/v1/kv/.
+	url string
+	// keyPrefix is the path under which the keys of the service live.
+	keyPrefix string
+	// prefix is the prefix for the names of the variables.
+	prefix string
+	// client is the HTTP client.
+	client *http.Client
+	// header holds extra headers sent with each request.
+	header http.Header
+	// timeout is the timeout of each request.
+	timeout time.Duration
+	// pollInterval is the interval at which Watch polls the store.
+	pollInterval time.Duration
+	// onError, if not nil, is called with each error fetching the keys when
+	// the source falls back to the last-known values.
+	onError func(error)
+	// lock guards values.
+	lock sync.RWMutex
+	// values holds the last-known values, keyed by full key.
+	values map[string]string
+}
+
+var _ Source = (*httpSource)(nil)
+var _ Reloader = (*httpSource)(nil)
+var _ Stager = (*httpSource)(nil)
+var _ Watcher = (*httpSource)(nil)
+
+// HTTPSourceOption is an option for an HTTP source.
+type HTTPSourceOption func(*httpSource) error
+
+// WithKeyPrefix returns an HTTPSourceOption that sets the path under which
+// the keys of the service live, such as "services/billing/". Only keys under
+// the path are fetched. The default is "", the root of the store.
+func WithKeyPrefix(prefix string) HTTPSourceOption {
+	return func(s *httpSource) error {
+		s.keyPrefix = prefix
+		return nil
+	}
+}
+
+// WithHTTPClient returns an HTTPSourceOption that sets the HTTP client used
+// to fetch the keys. The default is http.DefaultClient.
+func WithHTTPClient(client *http.Client) HTTPSourceOption {
+	return func(s *httpSource) error {
+		if client == nil {
+			return status.ErrInvalidArgument
+		}
+		s.client = client
+		return nil
+	}
+}
+
+// WithHTTPHeader returns an HTTPSourceOption that adds a header to each
+// request, such as an access token.
+func WithHTTPHeader(key, value string) HTTPSourceOption {
+	return func(s *httpSource) error {
+		s.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithHTTPTimeout returns an HTTPSourceOption that sets the timeout of each
+// request. The default is 5 seconds.
+func WithHTTPTimeout(timeout time.Duration) HTTPSourceOption {
+	return func(s *httpSource) error {
+		if timeout <= 0 {
+			return status.ErrOutOfRange
+		}
+		s.timeout = timeout
+		return nil
+	}
+}
+
+// WithHTTPPollInterval returns an HTTPSourceOption that sets the interval at
+// which Watch polls the store for changes. The default is 30 seconds.
+func WithHTTPPollInterval(interval time.Duration) HTTPSourceOption {
+	return func(s *httpSource) error {
+		if interval <= 0 {
+			return status.ErrOutOfRange
+		}
+		s.pollInterval = interval
+		return nil
+	}
+}
+
+// WithHTTPErrorHandler returns an HTTPSourceOption that sets a function
+// called with each error fetching the keys when the source falls back to
+// the last-known values.
+func WithHTTPErrorHandler(f func(error)) HTTPSourceOption {
+	return func(s *httpSource) error {
+		s.onError = f
+		return nil
+	}
+}
+
+// NewHTTPSource creates a Source that reads configuration variables from a
+// key-value store over HTTP, using the Consul KV API: the keys under the key
+// prefix are fetched with a single GET of url + key prefix + "?recurse", which
+// returns a JSON array of objects with a Key and a base64-encoded Value.
+//
+// The variable with the given name is read from the key formed by the key
+// prefix (see WithKeyPrefix), the prefix set with SetPrefix, and the name.
+// Values are parsed from their text in the same way as environment
+// variables.
+//
+// The keys are fetched when the source is created, and cached; NewHTTPSource
+// fails if they cannot be fetched. Reload fetches them again. If a later
+// fetch fails, the source keeps the last-known values, and the error is
+// passed to the handler set with WithHTTPErrorHandler. The source implements
+// Reloader and Watcher; Watch polls the store for changes.
+func NewHTTPSource(url string, opts ...HTTPSourceOption) (Source, error) {
+	s := &httpSource{
+		url:          url,
+		client:       http.DefaultClient,
+		header:       make(http.Header),
+		timeout:      5 * time.Second,
+		pollInterval: 30 * time.Second,
+	}
+	s.textLoader = s.lookup
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	values, err := s.fetch(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.values = values
+	return s, nil
+}
+
+// SetPrefix sets the prefix for the names of the variables.
+func (s *httpSource) SetPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Reload fetches the keys again. If they cannot be fetched, the last-known
+// values are kept.
+func (s *httpSource) Reload() error {
+	_, commit, err := s.Stage()
+	if err != nil {
+		return err
+	}
+	commit()
+	return nil
+}
+
+// Stage fetches the keys again, without changing the source. It returns a
+// source holding the new values, and a function that applies them. If the
+// keys cannot be fetched, the staged source holds the last-known values.
+func (s *httpSource) Stage() (Source, func(), error) {
+	values, err := s.fetch(context.Background())
+	if err != nil {
+		s.fallBack(err)
+		return s, func() {}, nil
+	}
+	staged := &httpSource{keyPrefix: s.keyPrefix, prefix: s.prefix, values: values}
+	staged.textLoader = staged.lookup
+	return staged, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.values = values
+	}, nil
+}
+
+// Watch polls the store, and calls notify when the keys differ from the
+// last-known values. The last-known values change only when a reload is
+// applied, so a change that is rejected is reported again at each poll until
+// the store changes back or the change is accepted. It blocks until ctx is
+// canceled.
+func (s *httpSource) Watch(ctx context.Context, notify func()) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			values, err := s.fetch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.fallBack(err)
+				}
+				continue
+			}
+			s.lock.RLock()
+			changed := !equalMaps(values, s.values)
+			s.lock.RUnlock()
+			if changed {
+				notify()
+			}
+		}
+	}
+}
+
+// lookup returns the last-known value of the variable with the given name.
+func (s *httpSource) lookup(name string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, ok := s.values[s.keyPrefix+s.prefix+name]
+	return v, ok
+}
+
+// fallBack reports an error fetching the keys.
+func (s *httpSource) fallBack(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// kvPair is a key and value returned by the key-value API.
+type kvPair struct {
+	// Key is the full key.
+	Key string
+	// Value is the value. It is base64-encoded in JSON, and null for keys
+	// without a value.
+	Value []byte
+}
+
+// fetch fetches the keys under the key prefix.
+func (s *httpSource) fetch(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	u := strings.TrimSuffix(s.url, "/") + "/" + (&url.URL{Path: s.keyPrefix}).EscapedPath() + "?recurse"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range s.header {
+		req.Header[key] = values
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", status.ErrServerError, err)
+	}
+	defer resp.Body.Close()
+	values := make(map[string]string)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// There are no keys under the prefix.
+		return values, nil
+	case resp.StatusCode >= 500:
+		return nil, fmt.Errorf("%w: GET %s: %s", status.ErrServerError, u, resp.Status)
+	case resp.StatusCode >= 400:
+		return nil, fmt.Errorf("%w: GET %s: %s", status.ErrClientError, u, resp.Status)
+	}
+	var pairs []kvPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("%w: GET %s: %s", status.ErrServerError, u, err)
+	}
+	for _, p := range pairs {
+		if p.Value != nil {
+			values[p.Key] = string(p.Value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/config/kvtest"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// newTestKVServer returns a fake key-value store holding keys for two
+// services.
+func newTestKVServer(t *testing.T) *kvtest.Server {
+	t.Helper()
+	kv := kvtest.NewServer()
+	t.Cleanup(kv.Close)
+	kv.Set("services/billing/PORT", "9090")
+	kv.Set("services/billing/HOSTS", "a,b")
+	kv.Set("services/billing/APP_MESSAGE", "prefixed")
+	kv.Set("services/search/PORT", "7070")
+	return kv
+}
+
+// Test_HTTPSource tests loading variables from a key-value store.
+func Test_HTTPSource(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	src, err := NewHTTPSource(kv.URL(), WithKeyPrefix("services/billing/"), WithHTTPHeader("X-Consul-Token", "token"))
+	if err != nil {
+		t.Fatalf("NewHTTPSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewStringList("HOSTS", nil, "hosts"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if err := c.NewString("MESSAGE", "default", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+	if v := c.StringList("HOSTS"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("StringList() = %v; want [a b]", v)
+	}
+	if v := c.String("MESSAGE"); v != "default" {
+		t.Errorf("String() = %v; want default", v)
+	}
+	if v := kv.Requests(); v != 1 {
+		t.Errorf("Requests() = %v; want 1", v)
+	}
+	if v := kv.Header().Get("X-Consul-Token"); v != "token" {
+		t.Errorf("Header() = %q; want token", v)
+	}
+}
+
+// Test_HTTPSource_LoadPrefix tests that the load prefix is part of the key.
+func Test_HTTPSource_LoadPrefix(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	src, err := NewHTTPSource(kv.URL(), WithKeyPrefix("services/billing/"))
+	if err != nil {
+		t.Fatalf("NewHTTPSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src), WithLoadPrefix("APP_"))
+	if err := c.NewString("MESSAGE", "default", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("MESSAGE"); v != "prefixed" {
+		t.Errorf("String() = %v; want prefixed", v)
+	}
+}
+
+// Test_HTTPSource_NotFound tests that a missing key, or a key prefix without
+// keys, is not found.
+func Test_HTTPSource_NotFound(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	for _, prefix := range []string{"services/billing/", "services/missing/"} {
+		src, err := NewHTTPSource(kv.URL(), WithKeyPrefix(prefix))
+		if err != nil {
+			t.Fatalf("NewHTTPSource() = %v; want nil", err)
+		}
+		if _, err := src.LoadString("MISSING"); !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("LoadString() = %v; want %v", err, status.ErrNotFound)
+		}
+	}
+}
+
+// Test_HTTPSource_Unavailable tests that NewHTTPSource fails if the keys
+// cannot be fetched.
+func Test_HTTPSource_Unavailable(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	for code, want := range map[int]error{
+		http.StatusInternalServerError: status.ErrServerError,
+		http.StatusForbidden:           status.ErrClientError,
+	} {
+		kv.Fail(code)
+		if _, err := NewHTTPSource(kv.URL()); !errors.Is(err, want) {
+			t.Errorf("NewHTTPSource() = %v; want %v", err, want)
+		}
+	}
+}
+
+// Test_HTTPSource_Fallback tests that the last-known values are kept when
+// the store is unavailable.
+func Test_HTTPSource_Fallback(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	var lock sync.Mutex
+	var fetchErr error
+	src, err := NewHTTPSource(kv.URL(), WithKeyPrefix("services/billing/"), WithHTTPErrorHandler(func(err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		fetchErr = err
+	}))
+	if err != nil {
+		t.Fatalf("NewHTTPSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	kv.Set("services/billing/PORT", "9191")
+	kv.Fail(http.StatusServiceUnavailable)
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+	lock.Lock()
+	if !errors.Is(fetchErr, status.ErrServerError) {
+		t.Errorf("error handler got %v; want %v", fetchErr, status.ErrServerError)
+	}
+	lock.Unlock()
+	kv.Fail(0)
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9191 {
+		t.Errorf("Int() = %v; want 9191", v)
+	}
+}
+
+// Test_HTTPSource_Timeout tests that a slow store times out.
+func Test_HTTPSource_Timeout(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	start := time.Now()
+	if _, err := NewHTTPSource(server.URL, WithHTTPTimeout(50*time.Millisecond)); !errors.Is(err, status.ErrServerError) {
+		t.Errorf("NewHTTPSource() = %v; want %v", err, status.ErrServerError)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("NewHTTPSource() took %v; want about 50ms", elapsed)
+	}
+}
+
+// Test_HTTPSource_Watch tests that Watch reports changes to the keys.
+func Test_HTTPSource_Watch(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	src, err := NewHTTPSource(kv.URL(), WithKeyPrefix("services/billing/"), WithHTTPPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewHTTPSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Watch(ctx, func(err error) {
+			if err == nil && c.Int("PORT") == 9292 {
+				cancel()
+			}
+		})
+	}()
+	kv.Set("services/billing/PORT", "9292")
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 9292 {
+		t.Errorf("Int() = %v; want 9292", v)
+	}
+}
+
+// Test_HTTPSource_Watch_Rejected tests that Watch reports a change again
+// after a reload rejects it.
+func Test_HTTPSource_Watch_Rejected(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	src, err := NewHTTPSource(kv.URL(), WithKeyPrefix("services/billing/"), WithHTTPPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewHTTPSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 8080, "port", WithMaximumValue(9999)); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var rejected int
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Watch(ctx, func(err error) {
+			if err != nil {
+				rejected++
+			}
+			if rejected == 2 {
+				cancel()
+			}
+		})
+	}()
+	kv.Set("services/billing/PORT", "10000")
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() = %v; want nil", err)
+	}
+	if rejected != 2 {
+		t.Errorf("rejected reloads = %v; want 2", rejected)
+	}
+	if v := c.Int("PORT"); v != 9090 {
+		t.Errorf("Int() = %v; want 9090", v)
+	}
+}
+
+// Test_HTTPSource_Options tests invalid options.
+func Test_HTTPSource_Options(t *testing.T) {
+	t.Parallel()
+	kv := newTestKVServer(t)
+	for name, opt := range map[string]HTTPSourceOption{
+		"timeout":       WithHTTPTimeout(0),
+		"poll-interval": WithHTTPPollInterval(-time.Second),
+		"client":        WithHTTPClient(nil),
+	} {
+		if _, err := NewHTTPSource(kv.URL(), opt); err == nil {
+			t.Errorf("%s: NewHTTPSource() = nil; want error", name)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VariableType is the type of a configuration variable.
+type VariableType string
+
+const (
+	// TypeInt is the type of int variables.
+	TypeInt VariableType = "int"
+	// TypeString is the type of string variables.
+	TypeString VariableType = "string"
+	// TypeBool is the type of bool variables.
+	TypeBool VariableType = "bool"
+	// TypeDuration is the type of duration variables.
+	TypeDuration VariableType = "duration"
+	// TypeFloat is the type of float variables.
+	TypeFloat VariableType = "float"
+	// TypeByteSize is the type of byte size variables.
+	TypeByteSize VariableType = "bytesize"
+	// TypeStringList is the type of string list variables.
+	TypeStringList VariableType = "stringlist"
+	// TypeIntList is the type of int list variables.
+	TypeIntList VariableType = "intlist"
+	// TypeStringMap is the type of string map variables.
+	TypeStringMap VariableType = "stringmap"
+)
+
+// VariableInfo describes a registered configuration variable. Values are
+// formatted as text, as they would be written in the environment. The value
+// and default of a secret are replaced by Redacted.
+type VariableInfo struct {
+	// Name is the name of the variable.
+	Name string
+	// Key is the name of the variable in the source, including the load
+	// prefix.
+	Key string
+	// Type is the type of the variable.
+	Type VariableType
+	// Description is the description of the variable.
+	Description string
+	// Default is the default value of the variable.
+	Default string
+	// Value is the current value of the variable.
+	Value string
+	// Origin is the name of the layer of a chain source (see NewChainSource)
+	// that supplied the value. It is empty if the variable has its default
+	// value, or if the source is not a chain source.
+	Origin string
+	// Required is true if the variable must have a value in the source.
+	Required bool
+	// Secret is true if the variable is a secret.
+	Secret bool
+	// Minimum is the minimum value of a numeric variable, or empty if the
+	// variable has no minimum other than that of its type.
+	Minimum string
+	// Maximum is the maximum value of a numeric variable, or empty if the
+	// variable has no maximum other than that of its type.
+	Maximum string
+	// Aliases are the other names that the variable may be loaded from.
+	Aliases []string
+	// DeprecatedNames are the deprecated names that the variable may be
+	// loaded from.
+	DeprecatedNames []string
+	// Clamped is true if values outside the range are clamped.
+	Clamped bool
+	// AllowedValues is the set of values allowed for a string variable, or
+	// nil if any value is allowed.
+	AllowedValues []string
+	// CaseInsensitive is true if values are matched against AllowedValues
+	// without regard to case.
+	CaseInsensitive bool
+	// MinimumLength is the minimum number of elements of a list or map
+	// variable, or empty if there is no minimum.
+	MinimumLength string
+	// MaximumLength is the maximum number of elements of a list or map
+	// variable, or empty if there is no maximum.
+	MaximumLength string
+	// Separator separates the elements of a list or map variable.
+	Separator string
+	// KeyValueSeparator separates the key and value of each entry of a map
+	// variable.
+	KeyValueSeparator string
+	// defaultValue is the default value of the variable, as its own type.
+	defaultValue interface{}
+}
+
+// Constraints returns the constraints on the variable as short phrases, such
+// as ">= 1" or "one of: json, console".
+func (v VariableInfo) Constraints() []string {
+	var constraints []string
+	if v.Minimum != "" {
+		constraints = append(constraints, ">= "+v.Minimum)
+	}
+	if v.Maximum != "" {
+		constraints = append(constraints, "<= "+v.Maximum)
+	}
+	if v.Clamped {
+		constraints = append(constraints, "clamped")
+	}
+	if v.AllowedValues != nil {
+		allowed := "one of: " + strings.Join(v.AllowedValues, ", ")
+		if v.CaseInsensitive {
+			allowed += " (case-insensitive)"
+		}
+		constraints = append(constraints, allowed)
+	}
+	if v.MinimumLength != "" {
+		constraints = append(constraints, fmt.Sprintf("at least %s elements", v.MinimumLength))
+	}
+	if v.MaximumLength != "" {
+		constraints = append(constraints, fmt.Sprintf("at most %s elements", v.MaximumLength))
+	}
+	if v.KeyValueSeparator != "" {
+		constraints = append(constraints, fmt.Sprintf("entries separated by %q, as key%svalue", v.Separator, v.KeyValueSeparator))
+	} else if v.Separator != "" {
+		constraints = append(constraints, fmt.Sprintf("separated by %q", v.Separator))
+	}
+	return constraints
+}
+
+// Variables returns a description of every registered variable, in
+// registration order.
+func (c *configImpl) Variables() []VariableInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	vars := make([]VariableInfo, len(c.order))
+	for i, name := range c.order {
+		v := c.vars[name]
+		info := v.info()
+		info.Key = c.loadPrefix + name
+		info.Aliases = v.legacy().list(false)
+		info.DeprecatedNames = v.legacy().list(true)
+		origins := c.origins[name]
+		info.Origin = originOf(origins, name, info.Secret)
+		for _, l := range v.legacy().legacyNames {
+			if info.Origin != "" {
+				break
+			}
+			info.Origin = originOf(origins, l.name, info.Secret)
+		}
+		vars[i] = info
+	}
+	return vars
+}
+
+// originOf returns the name of the layer that supplied the value with the
+// given name, as recorded in origins. If no layer supplied it and secret is
+// true, it returns the name of the layer that supplied the name of the secret
+// file instead.
+func originOf(origins map[string]string, name string, secret bool) string {
+	origin := origins[name]
+	if origin == "" && secret {
+		origin = origins[name+SecretFileSuffix]
+	}
+	return origin
+}
+
+// formatLength formats a length constraint, or returns "" if it is limit.
+func formatLength(length, limit int) string {
+	if length == limit {
+		return ""
+	}
+	return fmt.Sprint(length)
+}
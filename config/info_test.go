@@ -0,0 +1,81 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// Test_Variables tests that Variables() describes every variable in
+// registration order.
+func Test_Variables(t *testing.T) {
+	t.Parallel()
+	env := newTestSource()
+	env.SetValue("APP_PORT", "9090")
+	env.SetValue("APP_PASSWORD", "hunter2")
+	file := newTestSource()
+	file.SetValue("APP_MODE", "Replica")
+	c := New(WithSource(NewChainSource(Layer{Name: "env", Source: env}, Layer{Name: "file", Source: file})), WithLoadPrefix("APP_"))
+	for _, err := range []error{
+		c.NewInt("PORT", 8080, "Port to listen on.", WithMinimumValue(1), WithMaximumValue(65535)),
+		c.NewString("MODE", "primary", "Replication mode.", WithAllowedValues("primary", "replica"), WithCaseInsensitiveValues()),
+		c.NewString("PASSWORD", "", "Database password.", WithSecret(), WithRequired()),
+		c.NewDuration("TIMEOUT", time.Second, "Request timeout.", WithMaximumDuration(time.Minute), WithDurationClamping()),
+		c.NewStringList("ORIGINS", []string{"a", "b"}, "Allowed origins.", WithMinimumStringListLength(1)),
+		c.NewStringMap("LABELS", map[string]string{"team": "core"}, "Static labels.", WithMaximumStringMapLength(4)),
+	} {
+		if err != nil {
+			t.Fatalf("New*() = %v; want nil", err)
+		}
+	}
+	expected := []VariableInfo{
+		{Name: "PORT", Key: "APP_PORT", Type: TypeInt, Description: "Port to listen on.", Default: "8080", Value: "9090", Origin: "env", Minimum: "1", Maximum: "65535"},
+		{Name: "MODE", Key: "APP_MODE", Type: TypeString, Description: "Replication mode.", Default: "primary", Value: "replica", Origin: "file", AllowedValues: []string{"primary", "replica"}, CaseInsensitive: true},
+		{Name: "PASSWORD", Key: "APP_PASSWORD", Type: TypeString, Description: "Database password.", Value: Redacted, Origin: "env", Required: true, Secret: true},
+		{Name: "TIMEOUT", Key: "APP_TIMEOUT", Type: TypeDuration, Description: "Request timeout.", Default: "1s", Value: "1s", Maximum: "1m0s", Clamped: true},
+		{Name: "ORIGINS", Key: "APP_ORIGINS", Type: TypeStringList, Description: "Allowed origins.", Default: "a,b", Value: "a,b", MinimumLength: "1", Separator: ","},
+		{Name: "LABELS", Key: "APP_LABELS", Type: TypeStringMap, Description: "Static labels.", Default: "team=core", Value: "team=core", MaximumLength: "4", Separator: ",", KeyValueSeparator: "="},
+	}
+	if diff := cmp.Diff(expected, c.Variables(), cmpopts.IgnoreUnexported(VariableInfo{})); diff != "" {
+		t.Errorf("Variables() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Test_VariableInfo_Constraints tests that Constraints() describes each
+// constraint.
+func Test_VariableInfo_Constraints(t *testing.T) {
+	t.Parallel()
+	info := VariableInfo{
+		Minimum:         "1",
+		Maximum:         "10",
+		Clamped:         true,
+		AllowedValues:   []string{"a", "b"},
+		CaseInsensitive: true,
+		MinimumLength:   "1",
+		MaximumLength:   "3",
+		Separator:       ";",
+	}
+	expected := []string{">= 1", "<= 10", "clamped", "one of: a, b (case-insensitive)", "at least 1 elements", "at most 3 elements", `separated by ";"`}
+	if diff := cmp.Diff(expected, info.Constraints()); diff != "" {
+		t.Errorf("Constraints() mismatch (-want +got):\n%s", diff)
+	}
+	if v := (VariableInfo{}).Constraints(); v != nil {
+		t.Errorf("Constraints() = %v; want nil", v)
+	}
+}
@@ -16,8 +16,8 @@ package config
 
 import (
 	"errors"
-	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/neuralnorthwest/mu/bug"
 	"github.com/neuralnorthwest/mu/status"
@@ -41,53 +41,62 @@ type Int struct {
 	clampValue bool
 	// validator is the validator for the variable.
 	validator func(int) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
 }
 
 // IntOption is an option for an int variable.
-type IntOption func(*configImpl, *Int) error
+type IntOption interface {
+	// applyInt applies the option to an int variable.
+	applyInt(c *configImpl, i *Int) error
+}
+
+// intOption is an IntOption implemented by a function.
+type intOption func(*configImpl, *Int) error
+
+// applyInt implements IntOption.
+func (o intOption) applyInt(c *configImpl, i *Int) error {
+	return o(c, i)
+}
 
 // WithMinimumValue returns an option that sets the minimum value for an int variable.
 func WithMinimumValue(min int) IntOption {
-	return func(c *configImpl, i *Int) error {
+	return intOption(func(c *configImpl, i *Int) error {
 		i.minimumValue = min
 		return nil
-	}
+	})
 }
 
 // WithMaximumValue returns an option that sets the maximum value for an int variable.
 func WithMaximumValue(max int) IntOption {
-	return func(c *configImpl, i *Int) error {
+	return intOption(func(c *configImpl, i *Int) error {
 		i.maximumValue = max
 		return nil
-	}
+	})
 }
 
 // WithIntValidator returns an option that sets the validator for an int variable.
 func WithIntValidator(f func(int) error) IntOption {
-	return func(c *configImpl, i *Int) error {
+	return intOption(func(c *configImpl, i *Int) error {
 		i.validator = f
 		return nil
-	}
+	})
 }
 
 // WithClamping returns an option that enables clamping for an int variable.
 func WithClamping() IntOption {
-	return func(c *configImpl, i *Int) error {
+	return intOption(func(c *configImpl, i *Int) error {
 		i.clampValue = true
 		return nil
-	}
+	})
 }
 
 // NewInt creates a new int variable.
 func (c *configImpl) NewInt(name string, defaultValue int, description string, options ...IntOption) error {
-	if _, ok := c.ints[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.strings[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.bools[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
+	if err := c.checkName(name); err != nil {
+		return err
 	}
 	i := &Int{
 		name:         name,
@@ -97,7 +106,7 @@ func (c *configImpl) NewInt(name string, defaultValue int, description string, o
 		maximumValue: math.MaxInt32,
 	}
 	for _, opt := range options {
-		if err := opt(c, i); err != nil {
+		if err := opt.applyInt(c, i); err != nil {
 			return err
 		}
 	}
@@ -114,29 +123,81 @@ func (c *configImpl) NewInt(name string, defaultValue int, description string, o
 			return err
 		}
 	}
-	i.value = i.defaultValue
-	v, err := c.source.LoadInt(name)
-	if err == nil {
-		v, err = clampOrError(v, i.minimumValue, i.maximumValue, i.clampValue)
-		if err != nil {
-			return err
+	return c.register(name, i)
+}
+
+// load implements variable.
+func (i *Int) load(src Source) (interface{}, error) {
+	v, err := src.LoadInt(i.name)
+	if errors.Is(err, status.ErrNotFound) {
+		if i.required {
+			return nil, errRequired
 		}
-		if i.validator != nil {
-			if err := i.validator(v); err != nil {
-				return err
-			}
+		return i.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v, err = clampOrError(v, i.minimumValue, i.maximumValue, i.clampValue)
+	if err != nil {
+		return nil, err
+	}
+	if i.validator != nil {
+		if err := i.validator(v); err != nil {
+			return nil, err
 		}
-		i.value = v
-	} else if !errors.Is(err, status.ErrNotFound) {
-		return err
 	}
-	c.ints[name] = i
-	return nil
+	return v, nil
+}
+
+// set implements variable.
+func (i *Int) set(value interface{}) bool {
+	old := i.value
+	i.value = value.(int)
+	return i.value != old
+}
+
+// format implements variable.
+func (i *Int) format() string {
+	return i.text(i.value)
+}
+
+// text implements variable.
+func (i *Int) text(value interface{}) string {
+	return strconv.Itoa(value.(int))
+}
+
+// initialValue implements variable.
+func (i *Int) initialValue() interface{} {
+	return i.defaultValue
+}
+
+// info implements variable.
+func (i *Int) info() VariableInfo {
+	info := VariableInfo{
+		Name:         i.name,
+		Type:         TypeInt,
+		Description:  i.description,
+		Default:      strconv.Itoa(i.defaultValue),
+		Value:        i.format(),
+		Required:     i.required,
+		Clamped:      i.clampValue,
+		defaultValue: i.defaultValue,
+	}
+	if i.minimumValue != math.MinInt32 {
+		info.Minimum = strconv.Itoa(i.minimumValue)
+	}
+	if i.maximumValue != math.MaxInt32 {
+		info.Maximum = strconv.Itoa(i.maximumValue)
+	}
+	return info
 }
 
 // Int returns the value of the int variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, Int returns 0.
 func (c *configImpl) Int(name string) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if i, ok := c.ints[name]; ok {
 		return i.value
 	}
@@ -147,6 +208,8 @@ func (c *configImpl) Int(name string) int {
 // DescribeInt returns the description of the int variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, DescribeInt returns an empty string.
 func (c *configImpl) DescribeInt(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if i, ok := c.ints[name]; ok {
 		return i.description
 	}
@@ -154,8 +217,13 @@ func (c *configImpl) DescribeInt(name string) string {
 	return ""
 }
 
+// number is the set of types that clampOrError accepts.
+type number interface {
+	~int | ~int64 | ~float64
+}
+
 // clampOrError clamps the value to the given range, or returns an error if the value is out of range.
-func clampOrError(value, min, max int, clamp bool) (int, error) {
+func clampOrError[T number](value, min, max T, clamp bool) (T, error) {
 	if value < min {
 		if clamp {
 			return min, nil
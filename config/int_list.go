@@ -0,0 +1,234 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// IntList is a configuration value that is a list of ints.
+type IntList struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value []int
+	// defaultValue is the default value of the variable.
+	defaultValue []int
+	// description is the description of the variable.
+	description string
+	// separator separates the elements of the list.
+	separator string
+	// minimumLength is the minimum number of elements.
+	minimumLength int
+	// maximumLength is the maximum number of elements.
+	maximumLength int
+	// validator is the validator for each element.
+	validator func(int) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// IntListOption is an option for a int list variable.
+type IntListOption interface {
+	// applyIntList applies the option to a int list variable.
+	applyIntList(c *configImpl, l *IntList) error
+}
+
+// intListOption is an IntListOption implemented by a function.
+type intListOption func(*configImpl, *IntList) error
+
+// applyIntList implements IntListOption.
+func (o intListOption) applyIntList(c *configImpl, l *IntList) error {
+	return o(c, l)
+}
+
+// WithIntListSeparator returns an option that sets the separator of the
+// elements of a int list variable. The default is ",".
+func WithIntListSeparator(sep string) IntListOption {
+	return intListOption(func(c *configImpl, l *IntList) error {
+		if err := checkSeparator(sep); err != nil {
+			return err
+		}
+		l.separator = sep
+		return nil
+	})
+}
+
+// WithMinimumIntListLength returns an option that sets the minimum number
+// of elements of a int list variable.
+func WithMinimumIntListLength(min int) IntListOption {
+	return intListOption(func(c *configImpl, l *IntList) error {
+		l.minimumLength = min
+		return nil
+	})
+}
+
+// WithMaximumIntListLength returns an option that sets the maximum number
+// of elements of a int list variable.
+func WithMaximumIntListLength(max int) IntListOption {
+	return intListOption(func(c *configImpl, l *IntList) error {
+		l.maximumLength = max
+		return nil
+	})
+}
+
+// WithIntListElementValidator returns an option that sets the validator
+// for each element of a int list variable.
+func WithIntListElementValidator(f func(int) error) IntListOption {
+	return intListOption(func(c *configImpl, l *IntList) error {
+		l.validator = f
+		return nil
+	})
+}
+
+// NewIntList creates a new int list variable.
+func (c *configImpl) NewIntList(name string, defaultValue []int, description string, options ...IntListOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	l := &IntList{
+		name:          name,
+		defaultValue:  copyList(defaultValue),
+		description:   description,
+		separator:     ",",
+		maximumLength: math.MaxInt,
+	}
+	for _, opt := range options {
+		if err := opt.applyIntList(c, l); err != nil {
+			return err
+		}
+	}
+	if err := checkLengthRange(l.minimumLength, l.maximumLength); err != nil {
+		return err
+	}
+	if err := l.validate(l.defaultValue); err != nil {
+		return err
+	}
+	return c.register(name, l)
+}
+
+// validate checks the length and the elements of a value of the variable.
+func (l *IntList) validate(value []int) error {
+	if err := checkLength(len(value), l.minimumLength, l.maximumLength); err != nil {
+		return err
+	}
+	if l.validator != nil {
+		for i, e := range value {
+			if err := l.validator(e); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// load implements variable.
+func (l *IntList) load(src Source) (interface{}, error) {
+	v, err := src.LoadIntList(l.name, l.separator)
+	if errors.Is(err, status.ErrNotFound) {
+		if l.required {
+			return nil, errRequired
+		}
+		return l.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v = copyList(v)
+	if err := l.validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (l *IntList) set(value interface{}) bool {
+	old := l.value
+	l.value = value.([]int)
+	return !equalLists(l.value, old)
+}
+
+// format implements variable.
+func (l *IntList) format() string {
+	return l.text(l.value)
+}
+
+// text implements variable.
+func (l *IntList) text(value interface{}) string {
+	return formatIntList(value.([]int), l.separator)
+}
+
+// initialValue implements variable.
+func (l *IntList) initialValue() interface{} {
+	return l.defaultValue
+}
+
+// info implements variable.
+func (l *IntList) info() VariableInfo {
+	return VariableInfo{
+		Name:          l.name,
+		Type:          TypeIntList,
+		Description:   l.description,
+		Default:       formatIntList(l.defaultValue, l.separator),
+		Value:         l.format(),
+		Required:      l.required,
+		MinimumLength: formatLength(l.minimumLength, 0),
+		MaximumLength: formatLength(l.maximumLength, math.MaxInt),
+		Separator:     l.separator,
+		defaultValue:  copyList(l.defaultValue),
+	}
+}
+
+// IntList returns a copy of the value of the int list variable with the given name. If the variable does not exist, it
+// calls bug.Bug. If bug.Bug does not panic, IntList returns nil.
+func (c *configImpl) IntList(name string) []int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if l, ok := c.intLists[name]; ok {
+		return copyList(l.value)
+	}
+	defer bug.Bugf("config: int list variable %q does not exist", name)
+	return nil
+}
+
+// DescribeIntList returns the description of the int list variable with the given name. If the variable does not exist,
+// it calls bug.Bug. If bug.Bug does not panic, DescribeIntList returns "".
+func (c *configImpl) DescribeIntList(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if l, ok := c.intLists[name]; ok {
+		return l.description
+	}
+	defer bug.Bugf("config: int list variable %q does not exist", name)
+	return ""
+}
+
+// formatIntList formats a list of ints, separated by sep.
+func formatIntList(list []int, sep string) string {
+	elems := make([]string, len(list))
+	for i, v := range list {
+		elems[i] = strconv.Itoa(v)
+	}
+	return strings.Join(elems, sep)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_IntList_FromTestSource tests that IntList() returns the value set in the
+// test source, and that the value is length checked and validated.
+func Test_IntList_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", "1, 2, 3")
+	src.SetValue("pipe", "4|5")
+	src.SetValue("short", "")
+	src.SetValue("negative", "1,-1")
+	src.SetValue("malformed", "1,a")
+	c := New(WithSource(src))
+	if err := c.NewIntList("test", []int{1}, "test"); err != nil {
+		t.Fatalf("NewIntList() = %v; want nil", err)
+	}
+	if v := c.IntList("test"); !cmp.Equal(v, []int{1, 2, 3}) {
+		t.Errorf("IntList() = %v; want [1 2 3]", v)
+	}
+	if err := c.NewIntList("pipe", nil, "test", WithIntListSeparator("|")); err != nil {
+		t.Fatalf("NewIntList() = %v; want nil", err)
+	}
+	if v := c.IntList("pipe"); !cmp.Equal(v, []int{4, 5}) {
+		t.Errorf("IntList() = %v; want [4 5]", v)
+	}
+	if err := c.NewIntList("short", []int{1}, "test", WithMinimumIntListLength(1)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewIntList() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	validator := WithIntListElementValidator(func(v int) error {
+		if v < 0 {
+			return status.ErrOutOfRange
+		}
+		return nil
+	})
+	if err := c.NewIntList("negative", nil, "test", validator); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewIntList() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewIntList("malformed", nil, "test"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewIntList() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewIntList("default", []int{1, 2}, "test", WithMaximumIntListLength(1)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewIntList() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewIntList("test", nil, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewIntList() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_IntList_Unknown tests that IntList() and DescribeIntList() call bug.Bug
+// if the variable name is unknown.
+func Test_IntList_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: int list variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.IntList("test")
+	_ = c.DescribeIntList("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeIntList tests that DescribeIntList() returns the correct value.
+func Test_DescribeIntList(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewIntList("test", nil, "description"); err != nil {
+		t.Fatalf("NewIntList() = %v; want nil", err)
+	}
+	if v := c.DescribeIntList("test"); v != "description" {
+		t.Errorf("DescribeIntList() = %v; want description", v)
+	}
+}
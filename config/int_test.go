@@ -54,9 +54,9 @@ func validatorIsNonNegative(value int) error {
 
 // intOptionError return an IntOption that returns an error.
 func intOptionError(err error) IntOption {
-	return func(c *configImpl, i *Int) error {
+	return intOption(func(c *configImpl, i *Int) error {
 		return err
-	}
+	})
 }
 
 // Test_NewInt tests the NewInt function.
@@ -275,7 +275,7 @@ func Test_NewInt(t *testing.T) {
 			}
 			// get diffs, allowing unexported fields to be compared
 			opts := []cmp.Option{
-				cmp.AllowUnexported(Int{}),
+				cmp.AllowUnexported(Int{}, legacyNames{}),
 				cmpopts.IgnoreFields(Int{}, "validator"),
 			}
 			diffs := cmp.Diff(tc.expected, c.ints[tc.varName], opts...)
@@ -0,0 +1,140 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kvtest provides a fake key-value store, served over HTTP, for
+// testing code that uses config.NewHTTPSource.
+package kvtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Server is a fake key-value store implementing the subset of the Consul KV
+// API used by config.NewHTTPSource.
+type Server struct {
+	// server is the HTTP server.
+	server *httptest.Server
+	// lock guards the fields below.
+	lock sync.Mutex
+	// values holds the values, keyed by full key.
+	values map[string]string
+	// statusCode, if not zero, is returned for every request instead of the
+	// values.
+	statusCode int
+	// requests is the number of requests served.
+	requests int
+	// header holds the headers of the last request.
+	header http.Header
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		values: make(map[string]string),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the URL of the key-value API, for use with
+// config.NewHTTPSource.
+func (s *Server) URL() string {
+	return s.server.URL + "/v1/kv/"
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Set sets the value of the given key.
+func (s *Server) Set(key, value string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values[key] = value
+}
+
+// Delete deletes the given key.
+func (s *Server) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.values, key)
+}
+
+// Fail makes the server respond to every request with the given status code,
+// to simulate an outage. Fail(0) restores normal operation.
+func (s *Server) Fail(statusCode int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.statusCode = statusCode
+}
+
+// Requests returns the number of requests served.
+func (s *Server) Requests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests
+}
+
+// Header returns the headers of the last request.
+func (s *Server) Header() http.Header {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.header.Clone()
+}
+
+// pair is a key and value, as returned by the API.
+type pair struct {
+	// Key is the full key.
+	Key string
+	// Value is the value, base64-encoded in JSON.
+	Value []byte
+}
+
+// serveHTTP serves a request for the keys under a prefix.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests++
+	s.header = r.Header.Clone()
+	if s.statusCode != 0 {
+		w.WriteHeader(s.statusCode)
+		return
+	}
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, "/v1/kv/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	_, recurse := r.URL.Query()["recurse"]
+	var pairs []pair
+	for key, value := range s.values {
+		if key == prefix || (recurse && strings.HasPrefix(key, prefix)) {
+			pairs = append(pairs, pair{Key: key, Value: []byte(value)})
+		}
+	}
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(pairs)
+}
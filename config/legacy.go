@@ -0,0 +1,285 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// legacyName is a name that a variable may be loaded from, other than its
+// own.
+type legacyName struct {
+	// name is the legacy name.
+	name string
+	// deprecated is true if a warning is due when the name is used.
+	deprecated bool
+}
+
+// legacyNames holds the legacy names of a variable, in the order they are
+// tried.
+type legacyNames struct {
+	// legacyNames are the legacy names of the variable.
+	legacyNames []legacyName
+}
+
+// legacy returns the legacy names of the variable.
+func (n *legacyNames) legacy() *legacyNames {
+	return n
+}
+
+// list returns the legacy names that are, or are not, deprecated.
+func (n *legacyNames) list(deprecated bool) []string {
+	var names []string
+	for _, l := range n.legacyNames {
+		if l.deprecated == deprecated {
+			names = append(names, l.name)
+		}
+	}
+	return names
+}
+
+// legacyOption is the VariableOption returned by WithAliases and
+// WithDeprecatedName.
+type legacyOption []legacyName
+
+// WithAliases returns an option that lets a variable be loaded from other
+// names, in addition to its own. The aliases are tried in order after the
+// name of the variable. If more than one of the names is set, they must have
+// the same value; otherwise, registration and reload fail with an error that
+// wraps status.ErrInvalidArgument. Aliases are full names: the prefix of a
+// sub-configuration is not prepended to them.
+func WithAliases(names ...string) VariableOption {
+	o := make(legacyOption, len(names))
+	for i, name := range names {
+		o[i] = legacyName{name: name}
+	}
+	return o
+}
+
+// WithDeprecatedName returns an option that lets a variable be loaded from a
+// name that it had before it was renamed. The deprecated name behaves like an
+// alias, except that when the value is loaded from it, the handler set with
+// WithDeprecationHandler is called, once per name.
+func WithDeprecatedName(name string) VariableOption {
+	return legacyOption{{name: name, deprecated: true}}
+}
+
+// apply adds the names to the legacy names of the variable with the given
+// name.
+func (o legacyOption) apply(name string, n *legacyNames) error {
+	for _, l := range o {
+		if l.name == "" || l.name == name {
+			return fmt.Errorf("%w: invalid legacy name %q for %s", status.ErrInvalidArgument, l.name, name)
+		}
+	}
+	n.legacyNames = append(n.legacyNames, o...)
+	return nil
+}
+
+// applyInt implements IntOption.
+func (o legacyOption) applyInt(c *configImpl, i *Int) error {
+	return o.apply(i.name, &i.legacyNames)
+}
+
+// applyString implements StringOption.
+func (o legacyOption) applyString(c *configImpl, s *String) error {
+	return o.apply(s.name, &s.legacyNames)
+}
+
+// applyBool implements BoolOption.
+func (o legacyOption) applyBool(c *configImpl, b *Bool) error {
+	return o.apply(b.name, &b.legacyNames)
+}
+
+// applyDuration implements DurationOption.
+func (o legacyOption) applyDuration(c *configImpl, d *Duration) error {
+	return o.apply(d.name, &d.legacyNames)
+}
+
+// applyFloat implements FloatOption.
+func (o legacyOption) applyFloat(c *configImpl, f *Float) error {
+	return o.apply(f.name, &f.legacyNames)
+}
+
+// applyByteSize implements ByteSizeOption.
+func (o legacyOption) applyByteSize(c *configImpl, b *ByteSize) error {
+	return o.apply(b.name, &b.legacyNames)
+}
+
+// applyStringList implements StringListOption.
+func (o legacyOption) applyStringList(c *configImpl, l *StringList) error {
+	return o.apply(l.name, &l.legacyNames)
+}
+
+// applyIntList implements IntListOption.
+func (o legacyOption) applyIntList(c *configImpl, l *IntList) error {
+	return o.apply(l.name, &l.legacyNames)
+}
+
+// applyStringMap implements StringMapOption.
+func (o legacyOption) applyStringMap(c *configImpl, m *StringMap) error {
+	return o.apply(m.name, &m.legacyNames)
+}
+
+// WithDeprecationHandler returns an Option that sets a function that is
+// called when a variable is loaded from a deprecated name. It is called at
+// most once for each deprecated name, with the name of the variable and the
+// deprecated name.
+func WithDeprecationHandler(f func(name, deprecated string)) Option {
+	return func(c *configImpl) {
+		c.onDeprecated = f
+	}
+}
+
+// deprecated calls the deprecation handler, unless it was already called
+// for the deprecated name.
+func (c *configImpl) deprecated(name, deprecated string) {
+	if c.onDeprecated == nil {
+		return
+	}
+	c.lock.Lock()
+	warned := c.warned[deprecated]
+	c.warned[deprecated] = true
+	c.lock.Unlock()
+	if !warned {
+		c.onDeprecated(name, deprecated)
+	}
+}
+
+// legacySource returns the source to load the variable with the given name
+// from. If the variable has legacy names, it wraps src so that they are
+// tried too.
+func (c *configImpl) legacySource(src Source, name string, v variable) Source {
+	if len(v.legacy().legacyNames) == 0 {
+		return src
+	}
+	return &legacySource{Source: src, config: c, name: name, names: v.legacy()}
+}
+
+// legacySource is a Source that loads a variable from its legacy names when
+// it is loaded by name. Other variables are loaded from the underlying
+// source.
+type legacySource struct {
+	// Source is the underlying source.
+	Source
+	// config is the Config that owns the variable.
+	config *configImpl
+	// name is the name of the variable.
+	name string
+	// names are the legacy names of the variable.
+	names *legacyNames
+}
+
+// LoadInt implements Source.
+func (s *legacySource) LoadInt(name string) (int, error) {
+	return loadLegacy(s, name, s.Source.LoadInt)
+}
+
+// LoadString implements Source.
+func (s *legacySource) LoadString(name string) (string, error) {
+	return loadLegacy(s, name, s.Source.LoadString)
+}
+
+// LoadBool implements Source.
+func (s *legacySource) LoadBool(name string) (bool, error) {
+	return loadLegacy(s, name, s.Source.LoadBool)
+}
+
+// LoadDuration implements Source.
+func (s *legacySource) LoadDuration(name string) (time.Duration, error) {
+	return loadLegacy(s, name, s.Source.LoadDuration)
+}
+
+// LoadFloat implements Source.
+func (s *legacySource) LoadFloat(name string) (float64, error) {
+	return loadLegacy(s, name, s.Source.LoadFloat)
+}
+
+// LoadByteSize implements Source.
+func (s *legacySource) LoadByteSize(name string) (int64, error) {
+	return loadLegacy(s, name, s.Source.LoadByteSize)
+}
+
+// LoadStringList implements Source.
+func (s *legacySource) LoadStringList(name string, sep string) ([]string, error) {
+	return loadLegacy(s, name, func(name string) ([]string, error) {
+		return s.Source.LoadStringList(name, sep)
+	})
+}
+
+// LoadIntList implements Source.
+func (s *legacySource) LoadIntList(name string, sep string) ([]int, error) {
+	return loadLegacy(s, name, func(name string) ([]int, error) {
+		return s.Source.LoadIntList(name, sep)
+	})
+}
+
+// LoadStringMap implements Source.
+func (s *legacySource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	return loadLegacy(s, name, func(name string) (map[string]string, error) {
+		return s.Source.LoadStringMap(name, sep, kvSep)
+	})
+}
+
+// loadLegacy loads the value with the given name. If name is the name of
+// the variable, or the name of its secret file variable, the legacy names
+// are tried too, and it is an error if they have different values.
+func loadLegacy[T any](s *legacySource, name string, load func(name string) (T, error)) (T, error) {
+	var suffix string
+	switch name {
+	case s.name:
+	case s.name + SecretFileSuffix:
+		suffix = SecretFileSuffix
+	default:
+		return load(name)
+	}
+	value, err := load(name)
+	if err != nil && !errors.Is(err, status.ErrNotFound) {
+		return value, err
+	}
+	found := err == nil
+	from := name
+	var deprecated bool
+	for _, l := range s.names.legacyNames {
+		legacy := l.name + suffix
+		v, err := load(legacy)
+		if errors.Is(err, status.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return v, fmt.Errorf("%s: %w", legacy, err)
+		}
+		if !found {
+			value, found, from, deprecated = v, true, legacy, l.deprecated
+			continue
+		}
+		if !reflect.DeepEqual(v, value) {
+			var zero T
+			return zero, fmt.Errorf("%w: %s and %s are set to different values", status.ErrInvalidArgument, from, legacy)
+		}
+	}
+	if !found {
+		return value, err
+	}
+	if deprecated {
+		s.config.deprecated(s.name, from[:len(from)-len(suffix)])
+	}
+	return value, nil
+}
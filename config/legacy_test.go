@@ -0,0 +1,260 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_WithAliases_Case is a test case for Test_WithAliases.
+type Test_WithAliases_Case struct {
+	// name is the name of the test case.
+	name string
+	// values are the values in the source.
+	values map[string]string
+	// expected is the expected value of the variable.
+	expected int
+	// err is the expected registration error.
+	err error
+	// deprecated is the deprecated name that the handler is expected to be
+	// called with, or "" if it is not expected to be called.
+	deprecated string
+}
+
+// Test_WithAliases tests loading a variable from its legacy names.
+func Test_WithAliases(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_WithAliases_Case{
+		{
+			name:     "default",
+			expected: 8080,
+		},
+		{
+			name:     "name",
+			values:   map[string]string{"HTTP_PORT": "1"},
+			expected: 1,
+		},
+		{
+			name:     "alias",
+			values:   map[string]string{"LISTEN_PORT": "2"},
+			expected: 2,
+		},
+		{
+			name:       "deprecated",
+			values:     map[string]string{"PORT": "3"},
+			expected:   3,
+			deprecated: "PORT",
+		},
+		{
+			name:     "same-value",
+			values:   map[string]string{"HTTP_PORT": "4", "LISTEN_PORT": "4", "PORT": "4"},
+			expected: 4,
+		},
+		{
+			name:   "conflict",
+			values: map[string]string{"HTTP_PORT": "5", "PORT": "6"},
+			err:    status.ErrInvalidArgument,
+		},
+		{
+			name:   "alias-conflict",
+			values: map[string]string{"LISTEN_PORT": "5", "PORT": "6"},
+			err:    status.ErrInvalidArgument,
+		},
+		{
+			name:   "invalid",
+			values: map[string]string{"PORT": "x"},
+			err:    errors.New("PORT"),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			src := newTestSource()
+			for name, value := range tc.values {
+				src.SetValue(name, value)
+			}
+			var deprecated []string
+			c := New(WithSource(src), WithDeprecationHandler(func(name, legacy string) {
+				if name != "HTTP_PORT" {
+					t.Errorf("handler name = %v; want HTTP_PORT", name)
+				}
+				deprecated = append(deprecated, legacy)
+			}))
+			err := c.NewInt("HTTP_PORT", 8080, "port", WithAliases("LISTEN_PORT"), WithDeprecatedName("PORT"))
+			switch {
+			case tc.err == nil && err != nil:
+				t.Fatalf("NewInt() = %v; want nil", err)
+			case tc.err != nil && !errors.Is(err, tc.err) && (err == nil || !strings.Contains(err.Error(), tc.err.Error())):
+				t.Fatalf("NewInt() = %v; want %v", err, tc.err)
+			case tc.err != nil:
+				return
+			}
+			if v := c.Int("HTTP_PORT"); v != tc.expected {
+				t.Errorf("Int() = %v; want %v", v, tc.expected)
+			}
+			var want []string
+			if tc.deprecated != "" {
+				want = []string{tc.deprecated}
+			}
+			if diff := cmp.Diff(want, deprecated); diff != "" {
+				t.Errorf("deprecated names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_WithAliases_Types tests that every type of variable can be loaded
+// from a deprecated name.
+func Test_WithAliases_Types(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	for name, value := range map[string]string{
+		"OLD_INT": "1", "OLD_STRING": "s", "OLD_BOOL": "true", "OLD_DURATION": "1s", "OLD_FLOAT": "1.5",
+		"OLD_BYTE_SIZE": "1Ki", "OLD_STRING_LIST": "a", "OLD_INT_LIST": "1", "OLD_STRING_MAP": "a=1",
+	} {
+		src.SetValue(name, value)
+	}
+	var lock sync.Mutex
+	deprecated := map[string]string{}
+	c := New(WithSource(src), WithDeprecationHandler(func(name, legacy string) {
+		lock.Lock()
+		defer lock.Unlock()
+		deprecated[name] = legacy
+	}))
+	for i, err := range []error{
+		c.NewInt("INT", 0, "", WithDeprecatedName("OLD_INT")),
+		c.NewString("STRING", "", "", WithDeprecatedName("OLD_STRING")),
+		c.NewBool("BOOL", false, "", WithDeprecatedName("OLD_BOOL")),
+		c.NewDuration("DURATION", 0, "", WithDeprecatedName("OLD_DURATION")),
+		c.NewFloat("FLOAT", 0, "", WithDeprecatedName("OLD_FLOAT")),
+		c.NewByteSize("BYTE_SIZE", 0, "", WithDeprecatedName("OLD_BYTE_SIZE")),
+		c.NewStringList("STRING_LIST", nil, "", WithDeprecatedName("OLD_STRING_LIST")),
+		c.NewIntList("INT_LIST", nil, "", WithDeprecatedName("OLD_INT_LIST")),
+		c.NewStringMap("STRING_MAP", nil, "", WithDeprecatedName("OLD_STRING_MAP")),
+	} {
+		if err != nil {
+			t.Errorf("registration %d = %v; want nil", i, err)
+		}
+	}
+	expected := map[string]string{
+		"INT": "1", "STRING": "s", "BOOL": "true", "DURATION": "1s", "FLOAT": "1.5",
+		"BYTE_SIZE": "1024", "STRING_LIST": "a", "INT_LIST": "1", "STRING_MAP": "a=1",
+	}
+	if diff := cmp.Diff(expected, c.Dump()); diff != "" {
+		t.Errorf("Dump() mismatch (-want +got):\n%s", diff)
+	}
+	if len(deprecated) != len(expected) {
+		t.Errorf("handler called for %v; want every variable", deprecated)
+	}
+	for name, legacy := range deprecated {
+		if legacy != "OLD_"+name {
+			t.Errorf("handler(%v) = %v; want OLD_%v", name, legacy, name)
+		}
+	}
+}
+
+// Test_WithAliases_Reload tests that legacy names are used on reload, that
+// the deprecation handler is called once per name, and that a conflict
+// keeps the current value.
+func Test_WithAliases_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("OLD_NAME", "a")
+	calls := 0
+	c := New(WithSource(src), WithDeprecationHandler(func(name, legacy string) {
+		calls++
+	}))
+	if err := c.NewString("NAME", "", "name", WithDeprecatedName("OLD_NAME")); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	src.SetValue("OLD_NAME", "b")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.String("NAME"); v != "b" {
+		t.Errorf("String() = %v; want b", v)
+	}
+	if calls != 1 {
+		t.Errorf("handler calls = %v; want 1", calls)
+	}
+	src.SetValue("NAME", "c")
+	if err := c.Reload(); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Reload() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if v := c.String("NAME"); v != "b" {
+		t.Errorf("String() = %v; want b", v)
+	}
+}
+
+// Test_WithAliases_Secret tests that a secret may be read from a file named
+// by the secret file variable of a legacy name.
+func Test_WithAliases_Secret(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "password", "hunter2\n")
+	src := newTestSource()
+	src.SetValue("OLD_PASSWORD"+SecretFileSuffix, path)
+	c := New(WithSource(src))
+	if err := c.NewString("PASSWORD", "", "password", WithSecret(), WithDeprecatedName("OLD_PASSWORD")); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("PASSWORD"); v != "hunter2" {
+		t.Errorf("String() = %v; want hunter2", v)
+	}
+}
+
+// Test_WithAliases_Invalid tests that a variable cannot have its own name
+// or an empty name as a legacy name.
+func Test_WithAliases_Invalid(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewInt("PORT", 0, "", WithAliases("PORT")); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewInt() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewInt("PORT", 0, "", WithDeprecatedName("")); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewInt() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_WithAliases_Variables tests that Variables reports the legacy names
+// and the origin of a value loaded from a legacy name.
+func Test_WithAliases_Variables(t *testing.T) {
+	t.Parallel()
+	env := newTestSource()
+	env.SetValue("OLD_PORT", "1")
+	c := New(WithSource(NewChainSource(Layer{Name: "env", Source: env})))
+	if err := c.NewInt("PORT", 0, "port", WithAliases("LISTEN_PORT"), WithDeprecatedName("OLD_PORT")); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	info := c.Variables()[0]
+	if diff := cmp.Diff([]string{"LISTEN_PORT"}, info.Aliases); diff != "" {
+		t.Errorf("Aliases mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"OLD_PORT"}, info.DeprecatedNames); diff != "" {
+		t.Errorf("DeprecatedNames mismatch (-want +got):\n%s", diff)
+	}
+	if info.Origin != "env" {
+		t.Errorf("Origin = %v; want env", info.Origin)
+	}
+	constraints := docConstraints(info)
+	if diff := cmp.Diff([]string{"aliases: LISTEN_PORT", "deprecated names: OLD_PORT"}, constraints); diff != "" {
+		t.Errorf("docConstraints() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// checkLength returns an error if n is not between min and max.
+func checkLength(n, min, max int) error {
+	if n < min {
+		return fmt.Errorf("%w: %d elements; want at least %d", status.ErrOutOfRange, n, min)
+	}
+	if n > max {
+		return fmt.Errorf("%w: %d elements; want at most %d", status.ErrOutOfRange, n, max)
+	}
+	return nil
+}
+
+// checkLengthRange returns an error if min and max are not a valid length
+// range.
+func checkLengthRange(min, max int) error {
+	if min < 0 || max < 0 {
+		return status.ErrOutOfRange
+	}
+	if min > max {
+		return status.ErrInvalidRange
+	}
+	return nil
+}
+
+// checkSeparator returns an error if sep is not a valid separator.
+func checkSeparator(sep string) error {
+	if sep == "" {
+		return fmt.Errorf("%w: empty separator", status.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// copyList returns a copy of list that is never nil.
+func copyList[T any](list []T) []T {
+	return append(make([]T, 0, len(list)), list...)
+}
+
+// equalLists returns true if a and b have the same elements in the same
+// order.
+func equalLists[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/neuralnorthwest/mu/status"
 )
@@ -28,7 +29,7 @@ type register func(name string, defaultValue interface{}) error
 // with a name that has already been registered.
 func Test_Names(t *testing.T) {
 	c := New()
-	defaultvals := []interface{}{"hello", 1, true}
+	defaultvals := []interface{}{"hello", 1, true, time.Second, 1.5, int64(1024), []string(nil), []int(nil), map[string]string(nil)}
 	regfuncs := []register{
 		func(name string, defaultValue interface{}) error {
 			return c.NewString(name, defaultvals[0].(string), "")
@@ -39,6 +40,24 @@ func Test_Names(t *testing.T) {
 		func(name string, defaultValue interface{}) error {
 			return c.NewBool(name, defaultvals[2].(bool), "")
 		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewDuration(name, defaultvals[3].(time.Duration), "")
+		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewFloat(name, defaultvals[4].(float64), "")
+		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewByteSize(name, defaultvals[5].(int64), "")
+		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewStringList(name, defaultvals[6].([]string), "")
+		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewIntList(name, defaultvals[7].([]int), "")
+		},
+		func(name string, defaultValue interface{}) error {
+			return c.NewStringMap(name, defaultvals[8].(map[string]string), "")
+		},
 	}
 	for i, regfunc := range regfuncs[:len(regfuncs)-1] {
 		for j, regfunc2 := range regfuncs[i+1:] {
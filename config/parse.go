@@ -0,0 +1,147 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// byteSizeUnits maps lower-case byte size units to their multipliers.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+	"e":   1000 * 1000 * 1000 * 1000 * 1000 * 1000,
+	"eb":  1000 * 1000 * 1000 * 1000 * 1000 * 1000,
+	"ei":  1 << 60,
+	"eib": 1 << 60,
+}
+
+// ParseByteSize parses a byte size such as "512Mi", "2GB", "1.5 KiB" or
+// "1024". Decimal units (K, M, G, T, P, E, with an optional B) are powers of
+// 1000; binary units (Ki, Mi, Gi, Ti, Pi, Ei, with an optional B) are powers
+// of 1024. A number without a unit is a number of bytes. Units are
+// case-insensitive.
+func ParseByteSize(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	num, unit := str, ""
+	if i := strings.IndexFunc(str, unicode.IsLetter); i >= 0 {
+		num, unit = strings.TrimSpace(str[:i]), str[i:]
+	}
+	mult, ok := byteSizeUnits[strings.ToLower(unit)]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("%w: invalid byte size: %q", status.ErrInvalidArgument, s)
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n > math.MaxInt64/mult || n < math.MinInt64/mult {
+			return 0, fmt.Errorf("%w: byte size overflows int64: %q", status.ErrOutOfRange, s)
+		}
+		return n * mult, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid byte size: %q", status.ErrInvalidArgument, s)
+	}
+	v := f * float64(mult)
+	if v >= math.MaxInt64 || v < math.MinInt64 {
+		return 0, fmt.Errorf("%w: byte size overflows int64: %q", status.ErrOutOfRange, s)
+	}
+	return int64(v), nil
+}
+
+// parseFloat parses a float, rejecting NaN.
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) {
+		return 0, fmt.Errorf("%w: NaN is not a valid value", status.ErrInvalidArgument)
+	}
+	return f, nil
+}
+
+// SplitList splits s into a list of elements separated by sep. Surrounding
+// whitespace is trimmed from each element and empty elements are dropped, so
+// an empty string is an empty list.
+func SplitList(s, sep string) []string {
+	list := []string{}
+	for _, e := range strings.Split(s, sep) {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// ParseIntList parses s as a list of ints separated by sep, as split by
+// SplitList.
+func ParseIntList(s, sep string) ([]int, error) {
+	elems := SplitList(s, sep)
+	list := make([]int, len(elems))
+	for i, e := range elems {
+		n, err := strconv.Atoi(e)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid int list element %q", status.ErrInvalidArgument, e)
+		}
+		list[i] = n
+	}
+	return list, nil
+}
+
+// ParseStringMap parses s as a list of key-value pairs. Pairs are separated
+// by sep, as split by SplitList, and each key is separated from its value by
+// kvSep. Surrounding whitespace is trimmed from keys and values. Every pair
+// must have a non-empty key, and keys must be unique.
+func ParseStringMap(s, sep, kvSep string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, pair := range SplitList(s, sep) {
+		k, v, ok := strings.Cut(pair, kvSep)
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%w: invalid map entry %q", status.ErrInvalidArgument, pair)
+		}
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("%w: duplicate map key %q", status.ErrAlreadyExists, k)
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
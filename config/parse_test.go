@@ -0,0 +1,113 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_ParseByteSize_Case is a test case for Test_ParseByteSize.
+type Test_ParseByteSize_Case struct {
+	input    string
+	expected int64
+	err      error
+}
+
+// Test_ParseByteSize tests that ParseByteSize() returns the correct value.
+func Test_ParseByteSize(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_ParseByteSize_Case{
+		{input: "0", expected: 0},
+		{input: "1024", expected: 1024},
+		{input: "10B", expected: 10},
+		{input: "2k", expected: 2000},
+		{input: "2KB", expected: 2000},
+		{input: "2Ki", expected: 2048},
+		{input: "2KiB", expected: 2048},
+		{input: "512Mi", expected: 512 << 20},
+		{input: "2GB", expected: 2000000000},
+		{input: "2 Gi", expected: 2 << 30},
+		{input: " 1.5Ki ", expected: 1536},
+		{input: "1Ti", expected: 1 << 40},
+		{input: "1PB", expected: 1000000000000000},
+		{input: "7Ei", expected: 7 << 60},
+		{input: "-1", expected: -1},
+		{input: "8Ei", err: status.ErrOutOfRange},
+		{input: "9.5EB", err: status.ErrOutOfRange},
+		{input: "", err: status.ErrInvalidArgument},
+		{input: "Mi", err: status.ErrInvalidArgument},
+		{input: "12XB", err: status.ErrInvalidArgument},
+		{input: "1.2.3M", err: status.ErrInvalidArgument},
+	} {
+		v, err := ParseByteSize(tc.input)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("ParseByteSize(%q) = %v, %v; want %v", tc.input, v, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || v != tc.expected {
+			t.Errorf("ParseByteSize(%q) = %v, %v; want %v, nil", tc.input, v, err, tc.expected)
+		}
+	}
+}
+
+// Test_SplitList tests that SplitList() trims elements and drops empty ones.
+func Test_SplitList(t *testing.T) {
+	t.Parallel()
+	for input, expected := range map[string][]string{
+		"":           {},
+		" ":          {},
+		"a":          {"a"},
+		" a , b ,,c": {"a", "b", "c"},
+	} {
+		if v := SplitList(input, ","); !cmp.Equal(v, expected) {
+			t.Errorf("SplitList(%q) = %q; want %q", input, v, expected)
+		}
+	}
+}
+
+// Test_ParseIntList tests that ParseIntList() parses each element.
+func Test_ParseIntList(t *testing.T) {
+	t.Parallel()
+	if v, err := ParseIntList("1 | -2", "|"); err != nil || !cmp.Equal(v, []int{1, -2}) {
+		t.Errorf("ParseIntList() = %v, %v; want [1 -2], nil", v, err)
+	}
+	if _, err := ParseIntList("1,2.5", ","); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("ParseIntList() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_ParseStringMap tests that ParseStringMap() parses each pair.
+func Test_ParseStringMap(t *testing.T) {
+	t.Parallel()
+	v, err := ParseStringMap("a=1, b = x=y ,c=", ",", "=")
+	if expected := map[string]string{"a": "1", "b": "x=y", "c": ""}; err != nil || !cmp.Equal(v, expected) {
+		t.Errorf("ParseStringMap() = %v, %v; want %v, nil", v, err, expected)
+	}
+	for input, expected := range map[string]error{
+		"a":       status.ErrInvalidArgument,
+		"=1":      status.ErrInvalidArgument,
+		"a=1,a=2": status.ErrAlreadyExists,
+	} {
+		if _, err := ParseStringMap(input, ",", "="); !errors.Is(err, expected) {
+			t.Errorf("ParseStringMap(%q) = %v; want %v", input, err, expected)
+		}
+	}
+}
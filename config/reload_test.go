@@ -0,0 +1,288 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_Reload tests that Reload picks up new values and calls the change
+// callbacks of the variables that changed.
+func Test_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("PORT", "1")
+	src.SetValue("MESSAGE", "hello")
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewString("MESSAGE", "", "message"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewBool("DEBUG", false, "debug"); err != nil {
+		t.Fatalf("NewBool() = %v; want nil", err)
+	}
+	changed := map[string]int{}
+	for _, name := range []string{"PORT", "MESSAGE", "DEBUG"} {
+		name := name
+		if err := c.OnChange(name, func() { changed[name]++ }); err != nil {
+			t.Fatalf("OnChange() = %v; want nil", err)
+		}
+	}
+	src.SetValue("PORT", "2")
+	src.SetValue("DEBUG", "true")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 2 {
+		t.Errorf("Int() = %v; want 2", v)
+	}
+	if v := c.String("MESSAGE"); v != "hello" {
+		t.Errorf("String() = %v; want hello", v)
+	}
+	if v := c.Bool("DEBUG"); !v {
+		t.Errorf("Bool() = %v; want true", v)
+	}
+	if changed["PORT"] != 1 || changed["DEBUG"] != 1 || changed["MESSAGE"] != 0 {
+		t.Errorf("changed = %v; want PORT and DEBUG once", changed)
+	}
+	// Removing a value from the source restores the default.
+	delete(src.values, "MESSAGE")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.String("MESSAGE"); v != "" {
+		t.Errorf("String() = %q; want \"\"", v)
+	}
+	if changed["MESSAGE"] != 1 {
+		t.Errorf("changed[MESSAGE] = %v; want 1", changed["MESSAGE"])
+	}
+}
+
+// Test_Reload_Invalid tests that an invalid reload changes no values.
+func Test_Reload_Invalid(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("PORT", "1")
+	src.SetValue("NAME", "a")
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 0, "port", WithMaximumValue(10)); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewString("NAME", "a", "name", WithStringValidator(func(s string) error {
+		if s == "" {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	called := false
+	if err := c.OnChange("PORT", func() { called = true }); err != nil {
+		t.Fatalf("OnChange() = %v; want nil", err)
+	}
+	src.SetValue("PORT", "5")
+	src.SetValue("NAME", "")
+	err := c.Reload()
+	if !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Reload() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Errorf("Reload() = %v; want one error", err)
+	}
+	if v := c.Int("PORT"); v != 1 {
+		t.Errorf("Int() = %v; want 1", v)
+	}
+	if called {
+		t.Errorf("OnChange callback was called")
+	}
+	src.SetValue("PORT", "11")
+	src.SetValue("NAME", "b")
+	if err := c.Reload(); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("Reload() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if v := c.String("NAME"); v != "a" {
+		t.Errorf("String() = %v; want a", v)
+	}
+}
+
+// Test_OnChange_Unknown tests that OnChange returns an error for an unknown
+// variable.
+func Test_OnChange_Unknown(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.OnChange("X", func() {}); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("OnChange() = %v; want %v", err, status.ErrNotFound)
+	}
+}
+
+// Test_Reload_FileSource tests that Reload reads the file source again.
+func Test_Reload_FileSource(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "config.yaml", "PORT: 1\n")
+	src, err := NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	c := New(WithSource(NewChainSource(Layer{Name: "file", Source: src})))
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := os.WriteFile(path, []byte("PORT: 2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 2 {
+		t.Errorf("Int() = %v; want 2", v)
+	}
+	// A malformed file is rejected and the old values are kept.
+	if err := os.WriteFile(path, []byte("PORT: [\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() = nil; want error")
+	}
+	if v := c.Int("PORT"); v != 2 {
+		t.Errorf("Int() = %v; want 2", v)
+	}
+}
+
+// Test_Reload_Rejected tests that a rejected reload leaves the source as it
+// was, so that reloading the same data again is rejected again.
+func Test_Reload_Rejected(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "config.yaml", "PORT: 1\n")
+	src, err := NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	c := New(WithSource(NewChainSource(Layer{Name: "file", Source: src})))
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	c.Validate(func(c Config) error {
+		if c.Int("PORT") == 3 {
+			return Violation("port 3 is reserved", "PORT")
+		}
+		return nil
+	})
+	if err := os.WriteFile(path, []byte("PORT: 3\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := c.Reload(); err == nil {
+			t.Errorf("Reload() = nil; want error")
+		}
+		if v := c.Int("PORT"); v != 1 {
+			t.Errorf("Int() = %v; want 1", v)
+		}
+		if v, err := src.LoadInt("PORT"); err != nil || v != 1 {
+			t.Errorf("LoadInt() = %v, %v; want 1, nil", v, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte("PORT: 2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("PORT"); v != 2 {
+		t.Errorf("Int() = %v; want 2", v)
+	}
+	if v, err := src.LoadInt("PORT"); err != nil || v != 2 {
+		t.Errorf("LoadInt() = %v, %v; want 2, nil", v, err)
+	}
+}
+
+// Test_Watch tests that Watch reloads the configuration when the file
+// changes.
+func Test_Watch(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "config.yaml", "PORT: 1\n")
+	src, err := NewFileSource(path, WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFileSource() = %v; want nil", err)
+	}
+	c := New(WithSource(src))
+	if err := c.NewInt("PORT", 0, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	changed := make(chan struct{}, 1)
+	if err := c.OnChange("PORT", func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		t.Fatalf("OnChange() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Watch(ctx, func(err error) {
+			if err != nil {
+				t.Errorf("reload error: %v", err)
+			}
+		})
+	}()
+	// Keep growing the file until the change is seen, since Watch may take
+	// its first look at the file after an earlier write.
+	timeout := time.After(5 * time.Second)
+	content := "PORT: 20\n"
+	for seen := false; !seen; {
+		content += "#\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() = %v; want nil", err)
+		}
+		select {
+		case <-changed:
+			// The reload may see a partly written file, so wait for the
+			// final value.
+			seen = c.Int("PORT") == 20
+		case <-time.After(50 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timed out waiting for change")
+		}
+	}
+	if v := c.Int("PORT"); v != 20 {
+		t.Errorf("Int() = %v; want 20", v)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() = %v; want nil", err)
+	}
+}
+
+// Test_Watch_NotImplemented tests that Watch returns status.ErrNotImplemented
+// if the source cannot be watched.
+func Test_Watch_NotImplemented(t *testing.T) {
+	t.Parallel()
+	for _, src := range []Source{newNullSource(), NewChainSource(Layer{Name: "null", Source: newNullSource()})} {
+		c := New(WithSource(src))
+		if err := c.Watch(context.Background(), nil); !errors.Is(err, status.ErrNotImplemented) {
+			t.Errorf("Watch() = %v; want %v", err, status.ErrNotImplemented)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// errRequired is returned when a required variable has no value in the
+// source.
+var errRequired = fmt.Errorf("%w: required variable is not set", status.ErrNotFound)
+
+// VariableOption is an option that applies to variables of every type.
+type VariableOption interface {
+	IntOption
+	StringOption
+	BoolOption
+	DurationOption
+	FloatOption
+	ByteSizeOption
+	StringListOption
+	IntListOption
+	StringMapOption
+}
+
+// requiredOption is the VariableOption returned by WithRequired.
+type requiredOption struct{}
+
+// WithRequired returns an option that makes a variable required. If the
+// source does not have a value for a required variable, registration and
+// reload fail with an error that wraps status.ErrNotFound, instead of using
+// the default value.
+func WithRequired() VariableOption {
+	return requiredOption{}
+}
+
+// applyInt implements IntOption.
+func (requiredOption) applyInt(c *configImpl, i *Int) error {
+	i.required = true
+	return nil
+}
+
+// applyString implements StringOption.
+func (requiredOption) applyString(c *configImpl, s *String) error {
+	s.required = true
+	return nil
+}
+
+// applyBool implements BoolOption.
+func (requiredOption) applyBool(c *configImpl, b *Bool) error {
+	b.required = true
+	return nil
+}
+
+// applyDuration implements DurationOption.
+func (requiredOption) applyDuration(c *configImpl, d *Duration) error {
+	d.required = true
+	return nil
+}
+
+// applyFloat implements FloatOption.
+func (requiredOption) applyFloat(c *configImpl, f *Float) error {
+	f.required = true
+	return nil
+}
+
+// applyByteSize implements ByteSizeOption.
+func (requiredOption) applyByteSize(c *configImpl, b *ByteSize) error {
+	b.required = true
+	return nil
+}
+
+// applyStringList implements StringListOption.
+func (requiredOption) applyStringList(c *configImpl, l *StringList) error {
+	l.required = true
+	return nil
+}
+
+// applyIntList implements IntListOption.
+func (requiredOption) applyIntList(c *configImpl, l *IntList) error {
+	l.required = true
+	return nil
+}
+
+// applyStringMap implements StringMapOption.
+func (requiredOption) applyStringMap(c *configImpl, m *StringMap) error {
+	m.required = true
+	return nil
+}
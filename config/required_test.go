@@ -0,0 +1,124 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// registerAll registers a required variable of each type.
+func registerAll(c Config) []error {
+	return []error{
+		c.NewInt("INT", 0, "", WithRequired()),
+		c.NewString("STRING", "", "", WithRequired()),
+		c.NewBool("BOOL", false, "", WithRequired()),
+		c.NewDuration("DURATION", 0, "", WithRequired()),
+		c.NewFloat("FLOAT", 0, "", WithRequired()),
+		c.NewByteSize("BYTE_SIZE", 0, "", WithRequired()),
+		c.NewStringList("STRING_LIST", nil, "", WithRequired()),
+		c.NewIntList("INT_LIST", nil, "", WithRequired()),
+		c.NewStringMap("STRING_MAP", nil, "", WithRequired()),
+	}
+}
+
+// Test_WithRequired tests that registering a required variable of any type
+// fails if the source does not have a value.
+func Test_WithRequired(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	for i, err := range registerAll(c) {
+		if !errors.Is(err, status.ErrNotFound) {
+			t.Errorf("registration %d = %v; want %v", i, err, status.ErrNotFound)
+		} else if !strings.Contains(err.Error(), "required") {
+			t.Errorf("registration %d = %v; want required variable error", i, err)
+		}
+	}
+	if err := c.NewString("STRING", "", "", WithRequired()); !strings.HasPrefix(err.Error(), "STRING: ") {
+		t.Errorf("NewString() = %v; want error naming STRING", err)
+	}
+}
+
+// Test_WithRequired_Set tests that registering a required variable succeeds
+// if the source has a value.
+func Test_WithRequired_Set(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	for name, value := range map[string]string{
+		"INT": "1", "STRING": "s", "BOOL": "true", "DURATION": "1s", "FLOAT": "1.5",
+		"BYTE_SIZE": "1Ki", "STRING_LIST": "a", "INT_LIST": "1", "STRING_MAP": "a=1",
+	} {
+		src.SetValue(name, value)
+	}
+	c := New(WithSource(src))
+	for i, err := range registerAll(c) {
+		if err != nil {
+			t.Errorf("registration %d = %v; want nil", i, err)
+		}
+	}
+	if v := c.String("STRING"); v != "s" {
+		t.Errorf("String() = %v; want s", v)
+	}
+	delete(src.values, "STRING")
+	if err := c.Reload(); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Reload() = %v; want %v", err, status.ErrNotFound)
+	}
+	if v := c.String("STRING"); v != "s" {
+		t.Errorf("String() = %v; want s", v)
+	}
+}
+
+// Test_WithCollectErrors tests that errors loading values are collected and
+// returned by Err.
+func Test_WithCollectErrors(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("PORT", "abc")
+	c := New(WithSource(src), WithCollectErrors())
+	if err := c.Err(); err != nil {
+		t.Errorf("Err() = %v; want nil", err)
+	}
+	if err := c.NewInt("PORT", 80, "port"); err != nil {
+		t.Errorf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewString("DATABASE_URL", "none", "database url", WithRequired()); err != nil {
+		t.Errorf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewBool("DEBUG", true, "debug"); err != nil {
+		t.Errorf("NewBool() = %v; want nil", err)
+	}
+	if err := c.NewInt("PORT", 80, "port"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewInt() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+	if v := c.Int("PORT"); v != 80 {
+		t.Errorf("Int() = %v; want 80", v)
+	}
+	if v := c.String("DATABASE_URL"); v != "none" {
+		t.Errorf("String() = %v; want none", v)
+	}
+	var errs Errors
+	if err := c.Err(); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Err() = %v; want 2 errors", err)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "PORT: ") {
+		t.Errorf("Err()[0] = %v; want error for PORT", errs[0])
+	}
+	if !strings.HasPrefix(errs[1].Error(), "DATABASE_URL: ") || !errors.Is(errs[1], status.ErrNotFound) {
+		t.Errorf("Err()[1] = %v; want required error for DATABASE_URL", errs[1])
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Redacted replaces the value of a secret in Dump and in error messages.
+const Redacted = "[REDACTED]"
+
+// SecretFileSuffix is appended to the name of a secret variable to find the
+// variable that holds the path of a file containing the secret.
+const SecretFileSuffix = "_FILE"
+
+// WithSecret returns an option that marks a string variable as a secret. The
+// value of a secret is replaced by Redacted in Dump and in the errors returned
+// when the value is invalid.
+//
+// A secret may be read from a file, following the convention used for
+// Kubernetes and Docker secrets: if the source has a value for NAME_FILE, the
+// secret is read from the file at that path, with trailing newlines removed.
+// It is an error to set both NAME and NAME_FILE.
+func WithSecret() StringOption {
+	return stringOption(func(c *configImpl, s *String) error {
+		s.secret = true
+		return nil
+	})
+}
+
+// WithSecretRotation returns an option that marks a string variable as a
+// secret, like WithSecret, and makes Config.Watch check the secret file for
+// changes at the given interval. When the file changes, the configuration is
+// reloaded, so the new secret is picked up without a restart.
+func WithSecretRotation(interval time.Duration) StringOption {
+	return stringOption(func(c *configImpl, s *String) error {
+		if interval <= 0 {
+			return status.ErrOutOfRange
+		}
+		s.secret = true
+		s.rotationInterval = interval
+		return nil
+	})
+}
+
+// redactedError is an error about a secret. It keeps the original error for
+// errors.Is and errors.As, but does not include its message, which may
+// contain the secret.
+type redactedError struct {
+	err error
+}
+
+// Error implements error.
+func (e *redactedError) Error() string {
+	return "invalid value " + Redacted
+}
+
+// Unwrap returns the original error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// loadSecret loads the value of a secret variable, from the file named by
+// NAME_FILE if it is set.
+func loadSecret(src Source, name string) (string, error) {
+	path, err := src.LoadString(name + SecretFileSuffix)
+	if errors.Is(err, status.ErrNotFound) {
+		return src.LoadString(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := src.LoadString(name); !errors.Is(err, status.ErrNotFound) {
+		return "", fmt.Errorf("%w: both %s and %s%s are set", status.ErrInvalidArgument, name, name, SecretFileSuffix)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// watchSecretFile calls notify whenever the secret file of the given variable
+// changes, or the variable starts to name a different file. It blocks until
+// ctx is canceled.
+func watchSecretFile(ctx context.Context, src Source, s *String, notify func()) error {
+	last := statSecretFile(src, s.name)
+	ticker := time.NewTicker(s.rotationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if stat := statSecretFile(src, s.name); stat != last {
+				last = stat
+				notify()
+			}
+		}
+	}
+}
+
+// secretFileStat holds the path and fileStat of a secret file.
+type secretFileStat struct {
+	// path is the path of the file.
+	path string
+	// stat is the fileStat of the file.
+	stat fileStat
+}
+
+// statSecretFile returns the secretFileStat of the secret file of the given
+// variable.
+func statSecretFile(src Source, name string) secretFileStat {
+	path, err := src.LoadString(name + SecretFileSuffix)
+	if err != nil {
+		return secretFileStat{}
+	}
+	return secretFileStat{path: path, stat: statFile(path)}
+}
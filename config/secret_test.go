@@ -0,0 +1,197 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_Secret tests that the value of a secret is available to String() and
+// redacted by Dump().
+func Test_Secret(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("PASSWORD", "hunter2")
+	c := New(WithSource(src))
+	if err := c.NewString("PASSWORD", "", "password", WithSecret()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if err := c.NewString("USER", "admin", "user"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("PASSWORD"); v != "hunter2" {
+		t.Errorf("String() = %v; want hunter2", v)
+	}
+	expected := map[string]string{"PASSWORD": Redacted, "USER": "admin"}
+	if v := c.Dump(); !cmp.Equal(v, expected) {
+		t.Errorf("Dump() = %v; want %v", v, expected)
+	}
+}
+
+// Test_Secret_RedactedError tests that the errors for invalid secrets do not
+// contain the value.
+func Test_Secret_RedactedError(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("TOKEN", "hunter2")
+	c := New(WithSource(src))
+	err := c.NewString("TOKEN", "", "token", WithSecret(), WithAllowedValues("a", "b"))
+	if !errors.Is(err, status.ErrInvalidArgument) {
+		t.Fatalf("NewString() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("NewString() = %v; want redacted error", err)
+	}
+}
+
+// Test_Secret_File tests that a secret is read from the file named by
+// NAME_FILE.
+func Test_Secret_File(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "password", "from-file\n")
+	src := newTestSource()
+	src.SetValue("PASSWORD_FILE", path)
+	src.SetValue("BOTH", "value")
+	src.SetValue("BOTH_FILE", path)
+	src.SetValue("MISSING_FILE", path+".missing")
+	src.SetValue("PLAIN_FILE", path)
+	c := New(WithSource(src))
+	if err := c.NewString("PASSWORD", "", "password", WithSecret()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("PASSWORD"); v != "from-file" {
+		t.Errorf("String() = %q; want from-file", v)
+	}
+	if err := c.NewString("BOTH", "", "both", WithSecret()); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewString() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewString("MISSING", "", "missing", WithSecret()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewString() = %v; want %v", err, os.ErrNotExist)
+	}
+	if err := c.NewString("PLAIN", "default", "not a secret"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("PLAIN"); v != "default" {
+		t.Errorf("String() = %q; want default", v)
+	}
+	if err := os.WriteFile(path, []byte("rotated"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v; want nil", err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.String("PASSWORD"); v != "rotated" {
+		t.Errorf("String() = %q; want rotated", v)
+	}
+}
+
+// Test_Secret_Rotation tests that Watch() reloads the configuration when a
+// rotating secret file changes.
+func Test_Secret_Rotation(t *testing.T) {
+	t.Parallel()
+	path := writeConfigFile(t, "password", "old")
+	src := newTestSource()
+	src.SetValue("PASSWORD_FILE", path)
+	c := New(WithSource(src))
+	if err := c.NewString("PASSWORD", "", "password", WithSecretRotation(0)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewString() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := c.NewString("PASSWORD", "", "password", WithSecretRotation(10*time.Millisecond)); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	changed := make(chan struct{}, 1)
+	if err := c.OnChange("PASSWORD", func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		t.Fatalf("OnChange() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Watch(ctx, nil)
+	}()
+	// Keep growing the file until the change is seen, since Watch may take
+	// its first look at the file after an earlier write.
+	timeout := time.After(5 * time.Second)
+	content := "new"
+	for seen := false; !seen; {
+		content += "\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() = %v; want nil", err)
+		}
+		select {
+		case <-changed:
+			// The reload may see a partly written file, so wait for the
+			// final value.
+			seen = c.String("PASSWORD") == "new"
+		case <-time.After(50 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timed out waiting for change")
+		}
+	}
+	if v := c.String("PASSWORD"); v != "new" {
+		t.Errorf("String() = %q; want new", v)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() = %v; want nil", err)
+	}
+}
+
+// Test_Dump tests that Dump() formats the values of all variable types.
+func Test_Dump(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	for _, err := range []error{
+		c.NewInt("INT", 1, ""),
+		c.NewString("STRING", "s", ""),
+		c.NewBool("BOOL", true, ""),
+		c.NewDuration("DURATION", 90*time.Second, ""),
+		c.NewFloat("FLOAT", 0.5, ""),
+		c.NewByteSize("BYTE_SIZE", 1024, ""),
+		c.NewStringList("STRING_LIST", []string{"a", "b"}, ""),
+		c.NewIntList("INT_LIST", []int{1, 2}, "", WithIntListSeparator(";")),
+		c.NewStringMap("STRING_MAP", map[string]string{"b": "2", "a": "1"}, ""),
+	} {
+		if err != nil {
+			t.Fatalf("New*() = %v; want nil", err)
+		}
+	}
+	expected := map[string]string{
+		"INT":         "1",
+		"STRING":      "s",
+		"BOOL":        "true",
+		"DURATION":    "1m30s",
+		"FLOAT":       "0.5",
+		"BYTE_SIZE":   "1024",
+		"STRING_LIST": "a,b",
+		"INT_LIST":    "1;2",
+		"STRING_MAP":  "a=1,b=2",
+	}
+	if v := c.Dump(); !cmp.Equal(v, expected) {
+		t.Errorf("Dump() = %v; want %v", v, expected)
+	}
+}
@@ -16,6 +16,7 @@ package config
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/neuralnorthwest/mu/status"
 )
@@ -72,6 +73,62 @@ func (s *testSource) LoadBool(name string) (bool, error) {
 	return strconv.ParseBool(str)
 }
 
+// LoadDuration loads the value of the duration variable with the given name.
+func (s *testSource) LoadDuration(name string) (time.Duration, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return time.ParseDuration(str)
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (s *testSource) LoadFloat(name string) (float64, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return strconv.ParseFloat(str, 64)
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+func (s *testSource) LoadByteSize(name string) (int64, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return ParseByteSize(str)
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name.
+func (s *testSource) LoadStringList(name string, sep string) ([]string, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return SplitList(str, sep), nil
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+func (s *testSource) LoadIntList(name string, sep string) ([]int, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return ParseIntList(str, sep)
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name.
+func (s *testSource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	str, ok := s.values[s.prefix+name]
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return ParseStringMap(str, sep, kvSep)
+}
+
 // nullSource is a source that returns zero values.
 type nullSource struct{}
 
@@ -100,6 +157,38 @@ func (s *nullSource) LoadBool(name string) (bool, error) {
 	return false, status.ErrNotFound
 }
 
+// LoadDuration loads the value of the duration variable with the given name.
+func (s *nullSource) LoadDuration(name string) (time.Duration, error) {
+	return 0, status.ErrNotFound
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (s *nullSource) LoadFloat(name string) (float64, error) {
+	return 0, status.ErrNotFound
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+func (s *nullSource) LoadByteSize(name string) (int64, error) {
+	return 0, status.ErrNotFound
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name.
+func (s *nullSource) LoadStringList(name string, sep string) ([]string, error) {
+	return nil, status.ErrNotFound
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+func (s *nullSource) LoadIntList(name string, sep string) ([]int, error) {
+	return nil, status.ErrNotFound
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name.
+func (s *nullSource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	return nil, status.ErrNotFound
+}
+
 // errorSource is a source that returns an error.
 type errorSource struct {
 	err error
@@ -129,3 +218,35 @@ func (s *errorSource) LoadString(name string) (string, error) {
 func (s *errorSource) LoadBool(name string) (bool, error) {
 	return false, s.err
 }
+
+// LoadDuration loads the value of the duration variable with the given name.
+func (s *errorSource) LoadDuration(name string) (time.Duration, error) {
+	return 0, s.err
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (s *errorSource) LoadFloat(name string) (float64, error) {
+	return 0, s.err
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+func (s *errorSource) LoadByteSize(name string) (int64, error) {
+	return 0, s.err
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name.
+func (s *errorSource) LoadStringList(name string, sep string) ([]string, error) {
+	return nil, s.err
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+func (s *errorSource) LoadIntList(name string, sep string) ([]int, error) {
+	return nil, s.err
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name.
+func (s *errorSource) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	return nil, s.err
+}
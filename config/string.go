@@ -17,6 +17,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/neuralnorthwest/mu/bug"
 	"github.com/neuralnorthwest/mu/status"
@@ -34,29 +36,76 @@ type String struct {
 	description string
 	// validator is the validator for the variable.
 	validator func(string) error
+	// allowedValues is the set of values the variable may have. If it is
+	// nil, any value is allowed.
+	allowedValues []string
+	// caseInsensitive is true if values are matched against allowedValues
+	// without regard to case.
+	caseInsensitive bool
+	// secret is true if the value of the variable is sensitive.
+	secret bool
+	// rotationInterval is the interval at which Watch checks the secret file
+	// for changes. If it is zero, the file is not watched.
+	rotationInterval time.Duration
+	// required is true if the variable must have a value in the source.
+	required bool
+	// expander, if not nil, expands references to other variables in the
+	// value. It is set by WithExpansion.
+	expander *configImpl
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
 }
 
 // StringOption is an option for a string variable.
-type StringOption func(*configImpl, *String) error
+type StringOption interface {
+	// applyString applies the option to a string variable.
+	applyString(c *configImpl, s *String) error
+}
+
+// stringOption is a StringOption implemented by a function.
+type stringOption func(*configImpl, *String) error
+
+// applyString implements StringOption.
+func (o stringOption) applyString(c *configImpl, s *String) error {
+	return o(c, s)
+}
 
 // WithStringValidator returns an option that sets the validator for a string variable.
 func WithStringValidator(f func(string) error) StringOption {
-	return func(c *configImpl, s *String) error {
+	return stringOption(func(c *configImpl, s *String) error {
 		s.validator = f
 		return nil
-	}
+	})
+}
+
+// WithAllowedValues returns an option that restricts a string variable to the
+// given set of values. Any other value is rejected with
+// status.ErrInvalidArgument. The set is reported by AllowedValues.
+func WithAllowedValues(values ...string) StringOption {
+	return stringOption(func(c *configImpl, s *String) error {
+		if len(values) == 0 {
+			return fmt.Errorf("%w: empty set of allowed values", status.ErrInvalidArgument)
+		}
+		s.allowedValues = append([]string{}, values...)
+		return nil
+	})
+}
+
+// WithCaseInsensitiveValues returns an option that matches the values of a
+// string variable against the set given by WithAllowedValues without regard
+// to case. A matching value is replaced by the allowed value as declared, so
+// "JSON" becomes "json" if "json" is allowed.
+func WithCaseInsensitiveValues() StringOption {
+	return stringOption(func(c *configImpl, s *String) error {
+		s.caseInsensitive = true
+		return nil
+	})
 }
 
 // NewString creates a new string variable.
 func (c *configImpl) NewString(name string, defaultValue string, description string, options ...StringOption) error {
-	if _, ok := c.ints[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.strings[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
-	}
-	if _, ok := c.bools[name]; ok {
-		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
+	if err := c.checkName(name); err != nil {
+		return err
 	}
 	s := &String{
 		name:         name,
@@ -65,33 +114,165 @@ func (c *configImpl) NewString(name string, defaultValue string, description str
 		description:  description,
 	}
 	for _, o := range options {
-		if err := o(c, s); err != nil {
+		if err := o.applyString(c, s); err != nil {
 			return err
 		}
 	}
-	if s.validator != nil {
-		if err := s.validator(s.value); err != nil {
+	if err := s.checkAllowedValues(); err != nil {
+		return err
+	}
+	// With expansion, the default is checked after it is expanded, when it
+	// is loaded.
+	if s.expander == nil {
+		var err error
+		s.defaultValue, err = s.check(s.defaultValue)
+		if err != nil {
 			return err
 		}
 	}
-	v, err := c.source.LoadString(name)
-	if err == nil {
-		if s.validator != nil {
-			if err := s.validator(v); err != nil {
-				return err
-			}
+	s.value = s.defaultValue
+	return c.register(name, s)
+}
+
+// load implements variable.
+func (s *String) load(src Source) (interface{}, error) {
+	return s.loadExpanded(src, nil, s.secret)
+}
+
+// loadExpanded implements load. stack holds the names of the variables whose
+// expansion references this one, to detect cycles, and secret is true if the
+// variable at the bottom of the stack is a secret.
+func (s *String) loadExpanded(src Source, stack []string, secret bool) (interface{}, error) {
+	var v string
+	var err error
+	if s.secret {
+		v, err = loadSecret(src, s.name)
+	} else {
+		v, err = src.LoadString(s.name)
+	}
+	if errors.Is(err, status.ErrNotFound) {
+		if s.required {
+			return nil, errRequired
 		}
-		s.value = v
-	} else if !errors.Is(err, status.ErrNotFound) {
-		return err
+		if s.expander == nil {
+			return s.defaultValue, nil
+		}
+		v, err = s.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.expander != nil {
+		if v, err = s.expander.expand(v, src, append(stack[:len(stack):len(stack)], s.name), secret); err != nil {
+			return nil, err
+		}
+	}
+	return s.check(v)
+}
+
+// checkAllowedValues returns an error if the set of allowed values has
+// duplicates.
+func (s *String) checkAllowedValues() error {
+	seen := make(map[string]bool, len(s.allowedValues))
+	for _, v := range s.allowedValues {
+		key := v
+		if s.caseInsensitive {
+			key = strings.ToLower(v)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate allowed value %q", status.ErrInvalidArgument, v)
+		}
+		seen[key] = true
 	}
-	c.strings[name] = s
 	return nil
 }
 
+// check checks a value against the allowed values and the validator. It
+// returns the value as declared in the allowed values. The errors for secrets
+// are redacted.
+func (s *String) check(v string) (string, error) {
+	v, err := s.checkValue(v)
+	if err != nil && s.secret {
+		return "", &redactedError{err: err}
+	}
+	return v, err
+}
+
+// checkValue implements check.
+func (s *String) checkValue(v string) (string, error) {
+	if s.allowedValues != nil {
+		allowed := false
+		for _, a := range s.allowedValues {
+			if a == v || (s.caseInsensitive && strings.EqualFold(a, v)) {
+				v, allowed = a, true
+				break
+			}
+		}
+		if !allowed {
+			return "", fmt.Errorf("%w: %q is not one of %s", status.ErrInvalidArgument, v, strings.Join(s.allowedValues, ", "))
+		}
+	}
+	if s.validator != nil {
+		if err := s.validator(v); err != nil {
+			return "", err
+		}
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (s *String) set(value interface{}) bool {
+	old := s.value
+	s.value = value.(string)
+	return s.value != old
+}
+
+// format implements variable.
+func (s *String) format() string {
+	if s.secret {
+		return Redacted
+	}
+	return s.value
+}
+
+// text implements variable.
+func (s *String) text(value interface{}) string {
+	return value.(string)
+}
+
+// initialValue implements variable.
+func (s *String) initialValue() interface{} {
+	return s.defaultValue
+}
+
+// info implements variable.
+func (s *String) info() VariableInfo {
+	info := VariableInfo{
+		Name:            s.name,
+		Type:            TypeString,
+		Description:     s.description,
+		Default:         s.defaultValue,
+		Value:           s.format(),
+		Required:        s.required,
+		Secret:          s.secret,
+		CaseInsensitive: s.caseInsensitive,
+		defaultValue:    s.defaultValue,
+	}
+	if s.allowedValues != nil {
+		info.AllowedValues = copyList(s.allowedValues)
+	}
+	if s.secret && s.defaultValue != "" {
+		info.Default = Redacted
+		info.defaultValue = Redacted
+	}
+	return info
+}
+
 // String returns the value of the string variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, String returns "".
 func (c *configImpl) String(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if s, ok := c.strings[name]; ok {
 		return s.value
 	}
@@ -102,9 +283,26 @@ func (c *configImpl) String(name string) string {
 // DescribeString returns the description of the string variable with the given name. If the variable does not exist, it calls bug.Bug.
 // If bug.Bug does not panic, DescribeString returns "".
 func (c *configImpl) DescribeString(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if s, ok := c.strings[name]; ok {
 		return s.description
 	}
 	defer bug.Bugf("config: string variable %q does not exist", name)
 	return ""
 }
+
+// AllowedValues returns a copy of the set of values allowed for the string variable with the given name, or nil if any value
+// is allowed. If the variable does not exist, it calls bug.Bug. If bug.Bug does not panic, AllowedValues returns nil.
+func (c *configImpl) AllowedValues(name string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if s, ok := c.strings[name]; ok {
+		if s.allowedValues == nil {
+			return nil
+		}
+		return copyList(s.allowedValues)
+	}
+	defer bug.Bugf("config: string variable %q does not exist", name)
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// StringList is a configuration value that is a list of strings.
+type StringList struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value []string
+	// defaultValue is the default value of the variable.
+	defaultValue []string
+	// description is the description of the variable.
+	description string
+	// separator separates the elements of the list.
+	separator string
+	// minimumLength is the minimum number of elements.
+	minimumLength int
+	// maximumLength is the maximum number of elements.
+	maximumLength int
+	// validator is the validator for each element.
+	validator func(string) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// StringListOption is an option for a string list variable.
+type StringListOption interface {
+	// applyStringList applies the option to a string list variable.
+	applyStringList(c *configImpl, l *StringList) error
+}
+
+// stringListOption is a StringListOption implemented by a function.
+type stringListOption func(*configImpl, *StringList) error
+
+// applyStringList implements StringListOption.
+func (o stringListOption) applyStringList(c *configImpl, l *StringList) error {
+	return o(c, l)
+}
+
+// WithStringListSeparator returns an option that sets the separator of the
+// elements of a string list variable. The default is ",".
+func WithStringListSeparator(sep string) StringListOption {
+	return stringListOption(func(c *configImpl, l *StringList) error {
+		if err := checkSeparator(sep); err != nil {
+			return err
+		}
+		l.separator = sep
+		return nil
+	})
+}
+
+// WithMinimumStringListLength returns an option that sets the minimum number
+// of elements of a string list variable.
+func WithMinimumStringListLength(min int) StringListOption {
+	return stringListOption(func(c *configImpl, l *StringList) error {
+		l.minimumLength = min
+		return nil
+	})
+}
+
+// WithMaximumStringListLength returns an option that sets the maximum number
+// of elements of a string list variable.
+func WithMaximumStringListLength(max int) StringListOption {
+	return stringListOption(func(c *configImpl, l *StringList) error {
+		l.maximumLength = max
+		return nil
+	})
+}
+
+// WithStringListElementValidator returns an option that sets the validator
+// for each element of a string list variable.
+func WithStringListElementValidator(f func(string) error) StringListOption {
+	return stringListOption(func(c *configImpl, l *StringList) error {
+		l.validator = f
+		return nil
+	})
+}
+
+// NewStringList creates a new string list variable.
+func (c *configImpl) NewStringList(name string, defaultValue []string, description string, options ...StringListOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	l := &StringList{
+		name:          name,
+		defaultValue:  copyList(defaultValue),
+		description:   description,
+		separator:     ",",
+		maximumLength: math.MaxInt,
+	}
+	for _, opt := range options {
+		if err := opt.applyStringList(c, l); err != nil {
+			return err
+		}
+	}
+	if err := checkLengthRange(l.minimumLength, l.maximumLength); err != nil {
+		return err
+	}
+	if err := l.validate(l.defaultValue); err != nil {
+		return err
+	}
+	return c.register(name, l)
+}
+
+// validate checks the length and the elements of a value of the variable.
+func (l *StringList) validate(value []string) error {
+	if err := checkLength(len(value), l.minimumLength, l.maximumLength); err != nil {
+		return err
+	}
+	if l.validator != nil {
+		for i, e := range value {
+			if err := l.validator(e); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// load implements variable.
+func (l *StringList) load(src Source) (interface{}, error) {
+	v, err := src.LoadStringList(l.name, l.separator)
+	if errors.Is(err, status.ErrNotFound) {
+		if l.required {
+			return nil, errRequired
+		}
+		return l.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v = copyList(v)
+	if err := l.validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (l *StringList) set(value interface{}) bool {
+	old := l.value
+	l.value = value.([]string)
+	return !equalLists(l.value, old)
+}
+
+// format implements variable.
+func (l *StringList) format() string {
+	return l.text(l.value)
+}
+
+// text implements variable.
+func (l *StringList) text(value interface{}) string {
+	return strings.Join(value.([]string), l.separator)
+}
+
+// initialValue implements variable.
+func (l *StringList) initialValue() interface{} {
+	return l.defaultValue
+}
+
+// info implements variable.
+func (l *StringList) info() VariableInfo {
+	return VariableInfo{
+		Name:          l.name,
+		Type:          TypeStringList,
+		Description:   l.description,
+		Default:       strings.Join(l.defaultValue, l.separator),
+		Value:         l.format(),
+		Required:      l.required,
+		MinimumLength: formatLength(l.minimumLength, 0),
+		MaximumLength: formatLength(l.maximumLength, math.MaxInt),
+		Separator:     l.separator,
+		defaultValue:  copyList(l.defaultValue),
+	}
+}
+
+// StringList returns a copy of the value of the string list variable with the given name. If the variable does not exist, it
+// calls bug.Bug. If bug.Bug does not panic, StringList returns nil.
+func (c *configImpl) StringList(name string) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if l, ok := c.stringLists[name]; ok {
+		return copyList(l.value)
+	}
+	defer bug.Bugf("config: string list variable %q does not exist", name)
+	return nil
+}
+
+// DescribeStringList returns the description of the string list variable with the given name. If the variable does not exist,
+// it calls bug.Bug. If bug.Bug does not panic, DescribeStringList returns "".
+func (c *configImpl) DescribeStringList(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if l, ok := c.stringLists[name]; ok {
+		return l.description
+	}
+	defer bug.Bugf("config: string list variable %q does not exist", name)
+	return ""
+}
@@ -0,0 +1,256 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_NewStringList_Case is a test case for NewStringList.
+type Test_NewStringList_Case struct {
+	// name is the name of the test case.
+	name string
+	// defaultValue is the default value of the variable.
+	defaultValue []string
+	// options are the options for the variable.
+	options []StringListOption
+	// expected is the expected result.
+	expected *StringList
+	// err is the expected error.
+	err error
+}
+
+// Test_NewStringList tests the NewStringList function.
+func Test_NewStringList(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_NewStringList_Case{
+		{
+			name:         "basic case",
+			defaultValue: []string{"a", "b"},
+			expected: &StringList{
+				name:          "test",
+				value:         []string{"a", "b"},
+				defaultValue:  []string{"a", "b"},
+				description:   "test",
+				separator:     ",",
+				maximumLength: math.MaxInt,
+			},
+		},
+		{
+			name: "nil default value",
+			expected: &StringList{
+				name:          "test",
+				value:         []string{},
+				defaultValue:  []string{},
+				description:   "test",
+				separator:     ",",
+				maximumLength: math.MaxInt,
+			},
+		},
+		{
+			name:         "with separator and lengths",
+			defaultValue: []string{"a"},
+			options:      []StringListOption{WithStringListSeparator(";"), WithMinimumStringListLength(1), WithMaximumStringListLength(2)},
+			expected: &StringList{
+				name:          "test",
+				value:         []string{"a"},
+				defaultValue:  []string{"a"},
+				description:   "test",
+				separator:     ";",
+				minimumLength: 1,
+				maximumLength: 2,
+			},
+		},
+		{
+			name:    "empty separator",
+			options: []StringListOption{WithStringListSeparator("")},
+			err:     status.ErrInvalidArgument,
+		},
+		{
+			name:    "default value shorter than minimum length",
+			options: []StringListOption{WithMinimumStringListLength(1)},
+			err:     status.ErrOutOfRange,
+		},
+		{
+			name:         "default value longer than maximum length",
+			defaultValue: []string{"a", "b"},
+			options:      []StringListOption{WithMaximumStringListLength(1)},
+			err:          status.ErrOutOfRange,
+		},
+		{
+			name:    "negative minimum length",
+			options: []StringListOption{WithMinimumStringListLength(-1)},
+			err:     status.ErrOutOfRange,
+		},
+		{
+			name:    "minimum length greater than maximum length",
+			options: []StringListOption{WithMinimumStringListLength(2), WithMaximumStringListLength(1)},
+			err:     status.ErrInvalidRange,
+		},
+		{
+			name:         "with validator, default value invalid",
+			defaultValue: []string{"a", ""},
+			options: []StringListOption{WithStringListElementValidator(func(v string) error {
+				if v == "" {
+					return status.ErrInvalidArgument
+				}
+				return nil
+			})},
+			err: status.ErrInvalidArgument,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(WithSource(newNullSource())).(*configImpl)
+			err := c.NewStringList("test", tc.defaultValue, "test", tc.options...)
+			if err != nil {
+				if tc.err == nil {
+					t.Errorf("NewStringList() = %v; want nil", err)
+				} else if !errors.Is(err, tc.err) {
+					t.Errorf("NewStringList() = %v; want %v", err, tc.err)
+				}
+				return
+			}
+			if tc.err != nil {
+				t.Errorf("NewStringList() = nil; want %v", tc.err)
+				return
+			}
+			opts := []cmp.Option{
+				cmp.AllowUnexported(StringList{}, legacyNames{}),
+				cmpopts.IgnoreFields(StringList{}, "validator"),
+			}
+			if diff := cmp.Diff(tc.expected, c.stringLists["test"], opts...); diff != "" {
+				t.Errorf("NewStringList() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_NewStringList_AlreadyExists tests that NewStringList() returns an error
+// if the variable name already exists.
+func Test_NewStringList_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewStringList("test", nil, "test"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if err := c.NewStringList("test", nil, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewStringList() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_StringList_FromTestSource tests that StringList() returns the value set
+// in the test source, and that the value is length checked and validated.
+func Test_StringList_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", " a, b ,,c ")
+	src.SetValue("semicolon", "a;b")
+	src.SetValue("long", "a,b,c")
+	src.SetValue("invalid", "http://a,ftp://b")
+	c := New(WithSource(src))
+	if err := c.NewStringList("test", nil, "test"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if v := c.StringList("test"); !cmp.Equal(v, []string{"a", "b", "c"}) {
+		t.Errorf("StringList() = %v; want [a b c]", v)
+	}
+	if err := c.NewStringList("semicolon", nil, "test", WithStringListSeparator(";")); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if v := c.StringList("semicolon"); !cmp.Equal(v, []string{"a", "b"}) {
+		t.Errorf("StringList() = %v; want [a b]", v)
+	}
+	if err := c.NewStringList("long", nil, "test", WithMaximumStringListLength(2)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewStringList() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	validator := WithStringListElementValidator(func(v string) error {
+		if !strings.HasPrefix(v, "http://") {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})
+	err := c.NewStringList("invalid", nil, "test", validator)
+	if !errors.Is(err, status.ErrInvalidArgument) || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("NewStringList() = %v; want %v for element 1", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_StringList_Copy tests that StringList() returns a copy of the value.
+func Test_StringList_Copy(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	defaultValue := []string{"a"}
+	if err := c.NewStringList("test", defaultValue, "test"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	defaultValue[0] = "b"
+	c.StringList("test")[0] = "c"
+	if v := c.StringList("test"); !cmp.Equal(v, []string{"a"}) {
+		t.Errorf("StringList() = %v; want [a]", v)
+	}
+}
+
+// Test_StringList_FromEnv tests that StringList() returns the value set in the
+// environment.
+func Test_StringList_FromEnv(t *testing.T) {
+	t.Setenv("prefix-test", "a,b")
+	c := New(WithLoadPrefix("prefix-"))
+	if err := c.NewStringList("test", nil, "test"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if v := c.StringList("test"); !cmp.Equal(v, []string{"a", "b"}) {
+		t.Errorf("StringList() = %v; want [a b]", v)
+	}
+}
+
+// Test_StringList_Unknown tests that StringList() and DescribeStringList()
+// call bug.Bug if the variable name is unknown.
+func Test_StringList_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: string list variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.StringList("test")
+	_ = c.DescribeStringList("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeStringList tests that DescribeStringList() returns the correct
+// value.
+func Test_DescribeStringList(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewStringList("test", nil, "description"); err != nil {
+		t.Fatalf("NewStringList() = %v; want nil", err)
+	}
+	if v := c.DescribeStringList("test"); v != "description" {
+		t.Errorf("DescribeStringList() = %v; want description", v)
+	}
+}
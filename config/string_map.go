@@ -0,0 +1,283 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// StringMap is a configuration value that is a map of strings to strings.
+type StringMap struct {
+	// name is the name of the variable.
+	name string
+	// value is the value of the variable.
+	value map[string]string
+	// defaultValue is the default value of the variable.
+	defaultValue map[string]string
+	// description is the description of the variable.
+	description string
+	// separator separates the entries of the map.
+	separator string
+	// keyValueSeparator separates the key of each entry from its value.
+	keyValueSeparator string
+	// minimumLength is the minimum number of entries.
+	minimumLength int
+	// maximumLength is the maximum number of entries.
+	maximumLength int
+	// validator is the validator for each entry.
+	validator func(key, value string) error
+	// required is true if the variable must have a value in the source.
+	required bool
+	// legacyNames holds the legacy names of the variable.
+	legacyNames
+}
+
+// StringMapOption is an option for a string map variable.
+type StringMapOption interface {
+	// applyStringMap applies the option to a string map variable.
+	applyStringMap(c *configImpl, m *StringMap) error
+}
+
+// stringMapOption is a StringMapOption implemented by a function.
+type stringMapOption func(*configImpl, *StringMap) error
+
+// applyStringMap implements StringMapOption.
+func (o stringMapOption) applyStringMap(c *configImpl, m *StringMap) error {
+	return o(c, m)
+}
+
+// WithStringMapSeparator returns an option that sets the separator of the
+// entries of a string map variable. The default is ",".
+func WithStringMapSeparator(sep string) StringMapOption {
+	return stringMapOption(func(c *configImpl, m *StringMap) error {
+		if err := checkSeparator(sep); err != nil {
+			return err
+		}
+		m.separator = sep
+		return nil
+	})
+}
+
+// WithStringMapKeyValueSeparator returns an option that sets the separator of
+// the key and value of each entry of a string map variable. The default is
+// "=".
+func WithStringMapKeyValueSeparator(sep string) StringMapOption {
+	return stringMapOption(func(c *configImpl, m *StringMap) error {
+		if err := checkSeparator(sep); err != nil {
+			return err
+		}
+		m.keyValueSeparator = sep
+		return nil
+	})
+}
+
+// WithMinimumStringMapLength returns an option that sets the minimum number
+// of entries of a string map variable.
+func WithMinimumStringMapLength(min int) StringMapOption {
+	return stringMapOption(func(c *configImpl, m *StringMap) error {
+		m.minimumLength = min
+		return nil
+	})
+}
+
+// WithMaximumStringMapLength returns an option that sets the maximum number
+// of entries of a string map variable.
+func WithMaximumStringMapLength(max int) StringMapOption {
+	return stringMapOption(func(c *configImpl, m *StringMap) error {
+		m.maximumLength = max
+		return nil
+	})
+}
+
+// WithStringMapValidator returns an option that sets the validator for each
+// entry of a string map variable.
+func WithStringMapValidator(f func(key, value string) error) StringMapOption {
+	return stringMapOption(func(c *configImpl, m *StringMap) error {
+		m.validator = f
+		return nil
+	})
+}
+
+// NewStringMap creates a new string map variable.
+func (c *configImpl) NewStringMap(name string, defaultValue map[string]string, description string, options ...StringMapOption) error {
+	if err := c.checkName(name); err != nil {
+		return err
+	}
+	m := &StringMap{
+		name:              name,
+		defaultValue:      copyMap(defaultValue),
+		description:       description,
+		separator:         ",",
+		keyValueSeparator: "=",
+		maximumLength:     math.MaxInt,
+	}
+	for _, opt := range options {
+		if err := opt.applyStringMap(c, m); err != nil {
+			return err
+		}
+	}
+	if m.separator == m.keyValueSeparator {
+		return fmt.Errorf("%w: separators must differ", status.ErrInvalidArgument)
+	}
+	if err := checkLengthRange(m.minimumLength, m.maximumLength); err != nil {
+		return err
+	}
+	if err := m.validate(m.defaultValue); err != nil {
+		return err
+	}
+	return c.register(name, m)
+}
+
+// validate checks the length and the entries of a value of the variable.
+func (m *StringMap) validate(value map[string]string) error {
+	if err := checkLength(len(value), m.minimumLength, m.maximumLength); err != nil {
+		return err
+	}
+	if m.validator != nil {
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := m.validator(k, value[k]); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// load implements variable.
+func (m *StringMap) load(src Source) (interface{}, error) {
+	v, err := src.LoadStringMap(m.name, m.separator, m.keyValueSeparator)
+	if errors.Is(err, status.ErrNotFound) {
+		if m.required {
+			return nil, errRequired
+		}
+		return m.defaultValue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v = copyMap(v)
+	if err := m.validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// set implements variable.
+func (m *StringMap) set(value interface{}) bool {
+	old := m.value
+	m.value = value.(map[string]string)
+	return !equalMaps(m.value, old)
+}
+
+// format implements variable.
+func (m *StringMap) format() string {
+	return m.text(m.value)
+}
+
+// text implements variable.
+func (m *StringMap) text(value interface{}) string {
+	return m.formatMap(value.(map[string]string))
+}
+
+// formatMap formats a map as sorted key-value pairs, using the separators of
+// the variable.
+func (m *StringMap) formatMap(value map[string]string) string {
+	pairs := make([]string, 0, len(value))
+	for k, v := range value {
+		pairs = append(pairs, k+m.keyValueSeparator+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, m.separator)
+}
+
+// initialValue implements variable.
+func (m *StringMap) initialValue() interface{} {
+	return m.defaultValue
+}
+
+// info implements variable.
+func (m *StringMap) info() VariableInfo {
+	return VariableInfo{
+		Name:              m.name,
+		Type:              TypeStringMap,
+		Description:       m.description,
+		Default:           m.formatMap(m.defaultValue),
+		Value:             m.format(),
+		Required:          m.required,
+		MinimumLength:     formatLength(m.minimumLength, 0),
+		MaximumLength:     formatLength(m.maximumLength, math.MaxInt),
+		Separator:         m.separator,
+		KeyValueSeparator: m.keyValueSeparator,
+		defaultValue:      copyMap(m.defaultValue),
+	}
+}
+
+// StringMap returns a copy of the value of the string map variable with the given name. If the variable does not exist, it
+// calls bug.Bug. If bug.Bug does not panic, StringMap returns nil.
+func (c *configImpl) StringMap(name string) map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if m, ok := c.stringMaps[name]; ok {
+		return copyMap(m.value)
+	}
+	defer bug.Bugf("config: string map variable %q does not exist", name)
+	return nil
+}
+
+// DescribeStringMap returns the description of the string map variable with the given name. If the variable does not exist,
+// it calls bug.Bug. If bug.Bug does not panic, DescribeStringMap returns "".
+func (c *configImpl) DescribeStringMap(name string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if m, ok := c.stringMaps[name]; ok {
+		return m.description
+	}
+	defer bug.Bugf("config: string map variable %q does not exist", name)
+	return ""
+}
+
+// copyMap returns a copy of m that is never nil.
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// equalMaps returns true if a and b have the same entries.
+func equalMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
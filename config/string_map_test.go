@@ -0,0 +1,122 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/bug"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Test_StringMap_FromTestSource tests that StringMap() returns the value set in
+// the test source, and that the value is length checked and validated.
+func Test_StringMap_FromTestSource(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("test", "team=core, env = prod")
+	src.SetValue("custom", "a:1;b:2=3")
+	src.SetValue("long", "a=1,b=2")
+	src.SetValue("invalid", "a=1,b=")
+	src.SetValue("malformed", "a")
+	src.SetValue("duplicate", "a=1,a=2")
+	c := New(WithSource(src))
+	if err := c.NewStringMap("test", nil, "test"); err != nil {
+		t.Fatalf("NewStringMap() = %v; want nil", err)
+	}
+	if v := c.StringMap("test"); !cmp.Equal(v, map[string]string{"team": "core", "env": "prod"}) {
+		t.Errorf("StringMap() = %v; want map[env:prod team:core]", v)
+	}
+	if err := c.NewStringMap("custom", nil, "test", WithStringMapSeparator(";"), WithStringMapKeyValueSeparator(":")); err != nil {
+		t.Fatalf("NewStringMap() = %v; want nil", err)
+	}
+	if v := c.StringMap("custom"); !cmp.Equal(v, map[string]string{"a": "1", "b": "2=3"}) {
+		t.Errorf("StringMap() = %v; want map[a:1 b:2=3]", v)
+	}
+	if err := c.NewStringMap("long", nil, "test", WithMaximumStringMapLength(1)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewStringMap() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	validator := WithStringMapValidator(func(key, value string) error {
+		if value == "" {
+			return status.ErrInvalidArgument
+		}
+		return nil
+	})
+	err := c.NewStringMap("invalid", nil, "test", validator)
+	if !errors.Is(err, status.ErrInvalidArgument) || !strings.Contains(err.Error(), `"b"`) {
+		t.Errorf("NewStringMap() = %v; want %v for key b", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewStringMap("malformed", nil, "test"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewStringMap() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewStringMap("duplicate", nil, "test"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewStringMap() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+	if err := c.NewStringMap("same", nil, "test", WithStringMapKeyValueSeparator(",")); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewStringMap() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewStringMap("required", nil, "test", WithMinimumStringMapLength(1)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("NewStringMap() = %v; want %v", err, status.ErrOutOfRange)
+	}
+}
+
+// Test_StringMap_Copy tests that StringMap() returns a copy of the value.
+func Test_StringMap_Copy(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	defaultValue := map[string]string{"a": "1"}
+	if err := c.NewStringMap("test", defaultValue, "test"); err != nil {
+		t.Fatalf("NewStringMap() = %v; want nil", err)
+	}
+	defaultValue["a"] = "2"
+	c.StringMap("test")["a"] = "3"
+	if v := c.StringMap("test"); !cmp.Equal(v, map[string]string{"a": "1"}) {
+		t.Errorf("StringMap() = %v; want map[a:1]", v)
+	}
+}
+
+// Test_StringMap_Unknown tests that StringMap() and DescribeStringMap() call
+// bug.Bug if the variable name is unknown.
+func Test_StringMap_Unknown(t *testing.T) {
+	messages := []string{}
+	expectedMessage := "config: string map variable \"test\" does not exist"
+	oldHandler := bug.Handler()
+	defer bug.SetHandler(oldHandler)
+	bug.SetHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+	c := New()
+	_ = c.StringMap("test")
+	_ = c.DescribeStringMap("test")
+	if len(messages) != 2 || messages[0] != expectedMessage || messages[1] != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v twice", messages, expectedMessage)
+	}
+}
+
+// Test_DescribeStringMap tests that DescribeStringMap() returns the correct
+// value.
+func Test_DescribeStringMap(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newNullSource()))
+	if err := c.NewStringMap("test", nil, "description"); err != nil {
+		t.Fatalf("NewStringMap() = %v; want nil", err)
+	}
+	if v := c.DescribeStringMap("test"); v != "description" {
+		t.Errorf("DescribeStringMap() = %v; want description", v)
+	}
+}
@@ -44,9 +44,9 @@ type Test_NewString_Case struct {
 
 // stringOptionError return an StringOption that returns an error.
 func stringOptionError(err error) StringOption {
-	return func(c *configImpl, s *String) error {
+	return stringOption(func(c *configImpl, s *String) error {
 		return err
-	}
+	})
 }
 
 // Test_NewString tests the NewString function.
@@ -103,6 +103,64 @@ func Test_NewString(t *testing.T) {
 			expected: nil,
 			err:      status.ErrInvalidArgument,
 		},
+		{
+			name:         "with allowed values",
+			varName:      "test",
+			defaultValue: "json",
+			description:  "test",
+			options:      []StringOption{WithAllowedValues("json", "console")},
+			expected: &String{
+				name:          "test",
+				value:         "json",
+				defaultValue:  "json",
+				description:   "test",
+				allowedValues: []string{"json", "console"},
+			},
+			err: nil,
+		},
+		{
+			name:         "with allowed values, case insensitive",
+			varName:      "test",
+			defaultValue: "JSON",
+			description:  "test",
+			options:      []StringOption{WithAllowedValues("json", "console"), WithCaseInsensitiveValues()},
+			expected: &String{
+				name:            "test",
+				value:           "json",
+				defaultValue:    "json",
+				description:     "test",
+				allowedValues:   []string{"json", "console"},
+				caseInsensitive: true,
+			},
+			err: nil,
+		},
+		{
+			name:         "with allowed values, default value not allowed",
+			varName:      "test",
+			defaultValue: "JSON",
+			description:  "test",
+			options:      []StringOption{WithAllowedValues("json", "console")},
+			expected:     nil,
+			err:          status.ErrInvalidArgument,
+		},
+		{
+			name:         "with empty allowed values",
+			varName:      "test",
+			defaultValue: "json",
+			description:  "test",
+			options:      []StringOption{WithAllowedValues()},
+			expected:     nil,
+			err:          status.ErrInvalidArgument,
+		},
+		{
+			name:         "with duplicate allowed values, case insensitive",
+			varName:      "test",
+			defaultValue: "json",
+			description:  "test",
+			options:      []StringOption{WithAllowedValues("json", "JSON"), WithCaseInsensitiveValues()},
+			expected:     nil,
+			err:          status.ErrInvalidArgument,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			c := New().(*configImpl)
@@ -125,7 +183,7 @@ func Test_NewString(t *testing.T) {
 				return
 			}
 			opts := []cmp.Option{
-				cmp.AllowUnexported(String{}),
+				cmp.AllowUnexported(String{}, legacyNames{}),
 				cmpopts.IgnoreFields(String{}, "validator"),
 			}
 			if diff := cmp.Diff(tc.expected, s, opts...); diff != "" {
@@ -207,6 +265,34 @@ func Test_String_FromTestSource_Validation(t *testing.T) {
 	}
 }
 
+// Test_String_FromTestSource_AllowedValues tests that values loaded from the
+// test source are matched against the allowed values.
+func Test_String_FromTestSource_AllowedValues(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("LOG_FORMAT", "Console")
+	src.SetValue("MODE", "Replica")
+	c := New(WithSource(src))
+	if err := c.NewString("LOG_FORMAT", "json", "test", WithAllowedValues("json", "console"), WithCaseInsensitiveValues()); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.String("LOG_FORMAT"); v != "console" {
+		t.Errorf("String() = %v; want console", v)
+	}
+	if v := c.AllowedValues("LOG_FORMAT"); !cmp.Equal(v, []string{"json", "console"}) {
+		t.Errorf("AllowedValues() = %v; want [json console]", v)
+	}
+	if err := c.NewString("MODE", "primary", "test", WithAllowedValues("primary", "replica")); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("NewString() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := c.NewString("OTHER", "", "test"); err != nil {
+		t.Fatalf("NewString() = %v; want nil", err)
+	}
+	if v := c.AllowedValues("OTHER"); v != nil {
+		t.Errorf("AllowedValues() = %v; want nil", v)
+	}
+}
+
 // Test_String_FromErrorSource tests that String() returns the correct value when
 // the variable is set in the error source.
 func Test_String_FromErrorSource(t *testing.T) {
@@ -258,6 +344,11 @@ func Test_String_Unknown(t *testing.T) {
 	if message != expectedMessage {
 		t.Errorf("bug.Bug() = %v; want %v", message, expectedMessage)
 	}
+	message = ""
+	_ = c.AllowedValues("test")
+	if message != expectedMessage {
+		t.Errorf("bug.Bug() = %v; want %v", message, expectedMessage)
+	}
 }
 
 // Test_DescribeString tests that DescribeString() returns the correct value.
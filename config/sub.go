@@ -0,0 +1,251 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// subConfig is a view of a Config whose variable names are prefixed.
+type subConfig struct {
+	// parent is the Config that holds the variables.
+	parent Config
+	// prefix is prepended to the names of the variables.
+	prefix string
+}
+
+var _ Config = (*subConfig)(nil)
+
+// Sub returns a view of the Config whose variable names are prefixed with
+// prefix and "_". This lets several instances of a component each register
+// their own variables: Sub("CLIENT_A").NewDuration("TIMEOUT", ...) registers
+// CLIENT_A_TIMEOUT, and Sub("CLIENT_A").Duration("TIMEOUT") reads it.
+func (c *configImpl) Sub(prefix string) Config {
+	return &subConfig{parent: c, prefix: prefix + "_"}
+}
+
+// Sub returns a view of the Config whose variable names are prefixed with
+// the prefix of this view, prefix, and "_".
+func (s *subConfig) Sub(prefix string) Config {
+	return &subConfig{parent: s.parent, prefix: s.prefix + prefix + "_"}
+}
+
+// NewInt creates a new int variable.
+func (s *subConfig) NewInt(name string, defaultValue int, description string, options ...IntOption) error {
+	return s.parent.NewInt(s.prefix+name, defaultValue, description, options...)
+}
+
+// Int returns the value of the int variable with the given name.
+func (s *subConfig) Int(name string) int {
+	return s.parent.Int(s.prefix + name)
+}
+
+// DescribeInt returns the description of the int variable with the given
+// name.
+func (s *subConfig) DescribeInt(name string) string {
+	return s.parent.DescribeInt(s.prefix + name)
+}
+
+// NewString creates a new string variable.
+func (s *subConfig) NewString(name string, defaultValue string, description string, options ...StringOption) error {
+	return s.parent.NewString(s.prefix+name, defaultValue, description, options...)
+}
+
+// String returns the value of the string variable with the given name.
+func (s *subConfig) String(name string) string {
+	return s.parent.String(s.prefix + name)
+}
+
+// DescribeString returns the description of the string variable with the
+// given name.
+func (s *subConfig) DescribeString(name string) string {
+	return s.parent.DescribeString(s.prefix + name)
+}
+
+// AllowedValues returns the set of values allowed for the string variable
+// with the given name.
+func (s *subConfig) AllowedValues(name string) []string {
+	return s.parent.AllowedValues(s.prefix + name)
+}
+
+// NewBool creates a new bool variable.
+func (s *subConfig) NewBool(name string, defaultValue bool, description string, options ...BoolOption) error {
+	return s.parent.NewBool(s.prefix+name, defaultValue, description, options...)
+}
+
+// Bool returns the value of the bool variable with the given name.
+func (s *subConfig) Bool(name string) bool {
+	return s.parent.Bool(s.prefix + name)
+}
+
+// DescribeBool returns the description of the bool variable with the given
+// name.
+func (s *subConfig) DescribeBool(name string) string {
+	return s.parent.DescribeBool(s.prefix + name)
+}
+
+// NewDuration creates a new duration variable.
+func (s *subConfig) NewDuration(name string, defaultValue time.Duration, description string, options ...DurationOption) error {
+	return s.parent.NewDuration(s.prefix+name, defaultValue, description, options...)
+}
+
+// Duration returns the value of the duration variable with the given name.
+func (s *subConfig) Duration(name string) time.Duration {
+	return s.parent.Duration(s.prefix + name)
+}
+
+// DescribeDuration returns the description of the duration variable with the
+// given name.
+func (s *subConfig) DescribeDuration(name string) string {
+	return s.parent.DescribeDuration(s.prefix + name)
+}
+
+// NewFloat creates a new float variable.
+func (s *subConfig) NewFloat(name string, defaultValue float64, description string, options ...FloatOption) error {
+	return s.parent.NewFloat(s.prefix+name, defaultValue, description, options...)
+}
+
+// Float returns the value of the float variable with the given name.
+func (s *subConfig) Float(name string) float64 {
+	return s.parent.Float(s.prefix + name)
+}
+
+// DescribeFloat returns the description of the float variable with the given
+// name.
+func (s *subConfig) DescribeFloat(name string) string {
+	return s.parent.DescribeFloat(s.prefix + name)
+}
+
+// NewByteSize creates a new byte size variable.
+func (s *subConfig) NewByteSize(name string, defaultValue int64, description string, options ...ByteSizeOption) error {
+	return s.parent.NewByteSize(s.prefix+name, defaultValue, description, options...)
+}
+
+// ByteSize returns the value of the byte size variable with the given name.
+func (s *subConfig) ByteSize(name string) int64 {
+	return s.parent.ByteSize(s.prefix + name)
+}
+
+// DescribeByteSize returns the description of the byte size variable with the
+// given name.
+func (s *subConfig) DescribeByteSize(name string) string {
+	return s.parent.DescribeByteSize(s.prefix + name)
+}
+
+// NewStringList creates a new string list variable.
+func (s *subConfig) NewStringList(name string, defaultValue []string, description string, options ...StringListOption) error {
+	return s.parent.NewStringList(s.prefix+name, defaultValue, description, options...)
+}
+
+// StringList returns the value of the string list variable with the given
+// name.
+func (s *subConfig) StringList(name string) []string {
+	return s.parent.StringList(s.prefix + name)
+}
+
+// DescribeStringList returns the description of the string list variable
+// with the given name.
+func (s *subConfig) DescribeStringList(name string) string {
+	return s.parent.DescribeStringList(s.prefix + name)
+}
+
+// NewIntList creates a new int list variable.
+func (s *subConfig) NewIntList(name string, defaultValue []int, description string, options ...IntListOption) error {
+	return s.parent.NewIntList(s.prefix+name, defaultValue, description, options...)
+}
+
+// IntList returns the value of the int list variable with the given name.
+func (s *subConfig) IntList(name string) []int {
+	return s.parent.IntList(s.prefix + name)
+}
+
+// DescribeIntList returns the description of the int list variable with the
+// given name.
+func (s *subConfig) DescribeIntList(name string) string {
+	return s.parent.DescribeIntList(s.prefix + name)
+}
+
+// NewStringMap creates a new string map variable.
+func (s *subConfig) NewStringMap(name string, defaultValue map[string]string, description string, options ...StringMapOption) error {
+	return s.parent.NewStringMap(s.prefix+name, defaultValue, description, options...)
+}
+
+// StringMap returns the value of the string map variable with the given name.
+func (s *subConfig) StringMap(name string) map[string]string {
+	return s.parent.StringMap(s.prefix + name)
+}
+
+// DescribeStringMap returns the description of the string map variable with
+// the given name.
+func (s *subConfig) DescribeStringMap(name string) string {
+	return s.parent.DescribeStringMap(s.prefix + name)
+}
+
+// Variables returns a description of every variable whose name has the
+// prefix of the view, with the prefix removed from Name.
+func (s *subConfig) Variables() []VariableInfo {
+	var vars []VariableInfo
+	for _, v := range s.parent.Variables() {
+		if name, ok := s.trim(v.Name); ok {
+			v.Name = name
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// Err returns the errors collected by the parent Config.
+func (s *subConfig) Err() error {
+	return s.parent.Err()
+}
+
+// Dump returns the values of the variables whose names have the prefix of
+// the view, keyed by name with the prefix removed.
+func (s *subConfig) Dump() map[string]string {
+	dump := make(map[string]string)
+	for name, value := range s.parent.Dump() {
+		if name, ok := s.trim(name); ok {
+			dump[name] = value
+		}
+	}
+	return dump
+}
+
+// OnChange registers a function that is called after Reload changes the
+// value of the variable with the given name.
+func (s *subConfig) OnChange(name string, f func()) error {
+	return s.parent.OnChange(s.prefix+name, f)
+}
+
+// Reload reloads the values of all variables of the parent Config.
+func (s *subConfig) Reload() error {
+	return s.parent.Reload()
+}
+
+// Watch watches the parent Config.
+func (s *subConfig) Watch(ctx context.Context, onReload func(err error)) error {
+	return s.parent.Watch(ctx, onReload)
+}
+
+// trim removes the prefix of the view from name. It returns false if name
+// does not have the prefix.
+func (s *subConfig) trim(name string) (string, bool) {
+	if !strings.HasPrefix(name, s.prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, s.prefix), true
+}
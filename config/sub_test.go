@@ -0,0 +1,153 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// registerTestClient registers the variables of a component that may have
+// several instances.
+func registerTestClient(c Config) error {
+	if err := c.NewDuration("TIMEOUT", time.Second, "Request timeout."); err != nil {
+		return err
+	}
+	return c.NewString("URL", "http://localhost", "Server URL.")
+}
+
+// Test_Sub tests that two instances of a component can register the same
+// variables in sub-configs.
+func Test_Sub(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("APP_CLIENT_A_TIMEOUT", "5s")
+	src.SetValue("APP_CLIENT_B_URL", "http://b")
+	c := New(WithSource(src), WithLoadPrefix("APP_"))
+	a := c.Sub("CLIENT_A")
+	b := c.Sub("CLIENT_B")
+	for _, sub := range []Config{a, b} {
+		if err := registerTestClient(sub); err != nil {
+			t.Fatalf("registerTestClient() = %v; want nil", err)
+		}
+	}
+	if v := a.Duration("TIMEOUT"); v != 5*time.Second {
+		t.Errorf("Duration() = %v; want 5s", v)
+	}
+	if v := b.Duration("TIMEOUT"); v != time.Second {
+		t.Errorf("Duration() = %v; want 1s", v)
+	}
+	if v := b.String("URL"); v != "http://b" {
+		t.Errorf("String() = %v; want http://b", v)
+	}
+	if v := c.String("CLIENT_B_URL"); v != "http://b" {
+		t.Errorf("String() = %v; want http://b", v)
+	}
+	if v := a.DescribeDuration("TIMEOUT"); v != "Request timeout." {
+		t.Errorf("DescribeDuration() = %v; want Request timeout.", v)
+	}
+	var names []string
+	for _, v := range c.Variables() {
+		names = append(names, v.Name)
+	}
+	want := []string{"CLIENT_A_TIMEOUT", "CLIENT_A_URL", "CLIENT_B_TIMEOUT", "CLIENT_B_URL"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("Variables() mismatch (-want +got):\n%s", diff)
+	}
+	vars := a.Variables()
+	if len(vars) != 2 || vars[0].Name != "TIMEOUT" || vars[0].Key != "APP_CLIENT_A_TIMEOUT" {
+		t.Errorf("Variables() = %v; want TIMEOUT and URL", vars)
+	}
+	if diff := cmp.Diff(map[string]string{"TIMEOUT": "1s", "URL": "http://b"}, b.Dump()); diff != "" {
+		t.Errorf("Dump() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Test_Sub_Nested tests sub-configs of sub-configs.
+func Test_Sub_Nested(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("HTTP_CLIENT_PORT", "8081")
+	c := New(WithSource(src))
+	sub := c.Sub("HTTP").Sub("CLIENT")
+	if err := sub.NewInt("PORT", 80, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if v := sub.Int("PORT"); v != 8081 {
+		t.Errorf("Int() = %v; want 8081", v)
+	}
+	if v := c.Int("HTTP_CLIENT_PORT"); v != 8081 {
+		t.Errorf("Int() = %v; want 8081", v)
+	}
+}
+
+// Test_Sub_AlreadyExists tests that a sub-config variable conflicts with a
+// parent variable of the same full name.
+func Test_Sub_AlreadyExists(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newTestSource()))
+	if err := c.NewInt("DB_PORT", 5432, "port"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.Sub("DB").NewInt("PORT", 5432, "port"); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("NewInt() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+}
+
+// Test_Sub_Reload tests reloading and change callbacks through a sub-config.
+func Test_Sub_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	c := New(WithSource(src))
+	sub := c.Sub("WORKER")
+	if err := sub.NewInt("COUNT", 1, "count"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	called := false
+	if err := sub.OnChange("COUNT", func() { called = true }); err != nil {
+		t.Fatalf("OnChange() = %v; want nil", err)
+	}
+	src.SetValue("WORKER_COUNT", "4")
+	if err := sub.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := sub.Int("COUNT"); v != 4 {
+		t.Errorf("Int() = %v; want 4", v)
+	}
+	if !called {
+		t.Errorf("OnChange callback was not called")
+	}
+}
+
+// Test_Sub_Bind tests binding a struct to a sub-config.
+func Test_Sub_Bind(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("CACHE_SIZE", "64Mi")
+	c := New(WithSource(src))
+	var cfg struct {
+		Size int64 `mu:"SIZE" default:"1Mi"`
+	}
+	if err := Bind(c.Sub("CACHE"), &cfg); err != nil {
+		t.Fatalf("Bind() = %v; want nil", err)
+	}
+	if cfg.Size != 64<<20 {
+		t.Errorf("Size = %v; want %v", cfg.Size, 64<<20)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// textLoader implements the Load methods of Source for sources that hold the
+// values of variables as text, such as the environment. It looks up the text
+// of the variable with the given name, and reports whether it is set.
+type textLoader func(name string) (string, bool)
+
+// LoadInt loads the value of the int variable with the given name.
+func (l textLoader) LoadInt(name string) (int, error) {
+	str, ok := l(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return strconv.Atoi(str)
+}
+
+// LoadString loads the value of the string variable with the given name.
+func (l textLoader) LoadString(name string) (string, error) {
+	str, ok := l(name)
+	if !ok {
+		return "", status.ErrNotFound
+	}
+	return str, nil
+}
+
+// LoadBool loads the value of the bool variable with the given name.
+func (l textLoader) LoadBool(name string) (bool, error) {
+	str, ok := l(name)
+	if !ok {
+		return false, status.ErrNotFound
+	}
+	return strconv.ParseBool(str)
+}
+
+// LoadDuration loads the value of the duration variable with the given name.
+func (l textLoader) LoadDuration(name string) (time.Duration, error) {
+	str, ok := l(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return time.ParseDuration(str)
+}
+
+// LoadFloat loads the value of the float variable with the given name.
+func (l textLoader) LoadFloat(name string) (float64, error) {
+	str, ok := l(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return parseFloat(str)
+}
+
+// LoadByteSize loads the value of the byte size variable with the given name.
+func (l textLoader) LoadByteSize(name string) (int64, error) {
+	str, ok := l(name)
+	if !ok {
+		return 0, status.ErrNotFound
+	}
+	return ParseByteSize(str)
+}
+
+// LoadStringList loads the value of the string list variable with the given
+// name.
+func (l textLoader) LoadStringList(name string, sep string) ([]string, error) {
+	str, ok := l(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return SplitList(str, sep), nil
+}
+
+// LoadIntList loads the value of the int list variable with the given name.
+func (l textLoader) LoadIntList(name string, sep string) ([]int, error) {
+	str, ok := l(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return ParseIntList(str, sep)
+}
+
+// LoadStringMap loads the value of the string map variable with the given
+// name.
+func (l textLoader) LoadStringMap(name string, sep string, kvSep string) (map[string]string, error) {
+	str, ok := l(name)
+	if !ok {
+		return nil, status.ErrNotFound
+	}
+	return ParseStringMap(str, sep, kvSep)
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// ValidationError is a violation of a rule registered with Validate.
+type ValidationError struct {
+	// Variables names the variables involved in the violation, if known.
+	Variables []string
+	// Err describes the violation.
+	Err error
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	if len(e.Variables) == 0 {
+		return e.Err.Error()
+	}
+	return strings.Join(e.Variables, ", ") + ": " + e.Err.Error()
+}
+
+// Unwrap returns the violation.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Violation returns a *ValidationError for a violation of a rule involving
+// the given variables. The error wraps status.ErrInvalidArgument.
+func Violation(message string, variables ...string) error {
+	return &ValidationError{
+		Variables: variables,
+		Err:       fmt.Errorf("%w: %s", status.ErrInvalidArgument, message),
+	}
+}
+
+// Validate registers a function that validates the configuration as a whole.
+func (c *configImpl) Validate(f func(Config) error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.validators = append(c.validators, f)
+}
+
+// Check runs the validators registered with Validate against the current
+// values.
+func (c *configImpl) Check() error {
+	c.lock.RLock()
+	validators := append([]func(Config) error{}, c.validators...)
+	c.lock.RUnlock()
+	return runValidators(c, validators)
+}
+
+// Validate registers a validator with the parent Config. The validator is
+// given a view with the prefix of this view.
+func (s *subConfig) Validate(f func(Config) error) {
+	prefix := s.prefix
+	s.parent.Validate(func(c Config) error {
+		return f(&subConfig{parent: c, prefix: prefix})
+	})
+}
+
+// Check runs the validators of the parent Config.
+func (s *subConfig) Check() error {
+	return s.parent.Check()
+}
+
+// runValidators runs each of the validators against c. It returns an Errors
+// holding a *ValidationError for each violation, or nil if there are none.
+func runValidators(c Config, validators []func(Config) error) error {
+	var errs Errors
+	for _, f := range validators {
+		err := f(c)
+		if err == nil {
+			continue
+		}
+		var list Errors
+		if !errors.As(err, &list) {
+			list = Errors{err}
+		}
+		for _, err := range list {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				verr = &ValidationError{Err: err}
+			}
+			errs = append(errs, verr)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// snapshot returns a copy of the Config holding copies of the given
+// variables, with the given names, set to the given values, and loading from
+// src. Reload validates the values in the snapshot before applying them. The
+// caller must hold c.reloadLock.
+func (c *configImpl) snapshot(src Source, names []string, vars []variable, values []interface{}) *configImpl {
+	s := newConfigImpl()
+	s.loadPrefix = c.loadPrefix
+	s.source = src
+	for i, v := range vars {
+		var clone variable
+		switch v := v.(type) {
+		case *Int:
+			clone = cloneVariable(v)
+		case *String:
+			clone = cloneVariable(v)
+		case *Bool:
+			clone = cloneVariable(v)
+		case *Duration:
+			clone = cloneVariable(v)
+		case *Float:
+			clone = cloneVariable(v)
+		case *ByteSize:
+			clone = cloneVariable(v)
+		case *StringList:
+			clone = cloneVariable(v)
+		case *IntList:
+			clone = cloneVariable(v)
+		case *StringMap:
+			clone = cloneVariable(v)
+		}
+		clone.set(values[i])
+		s.add(names[i], clone)
+	}
+	return s
+}
+
+// cloneVariable returns a copy of v.
+func cloneVariable[T any](v *T) *T {
+	clone := *v
+	return &clone
+}
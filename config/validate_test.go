@@ -0,0 +1,215 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// registerTestWorkers registers MIN_WORKERS and MAX_WORKERS, and a validator
+// requiring MIN_WORKERS <= MAX_WORKERS.
+func registerTestWorkers(t *testing.T, c Config) {
+	t.Helper()
+	if err := c.NewInt("MIN_WORKERS", 1, "minimum workers"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	if err := c.NewInt("MAX_WORKERS", 4, "maximum workers"); err != nil {
+		t.Fatalf("NewInt() = %v; want nil", err)
+	}
+	c.Validate(func(c Config) error {
+		if c.Int("MIN_WORKERS") > c.Int("MAX_WORKERS") {
+			return Violation("must not exceed MAX_WORKERS", "MIN_WORKERS")
+		}
+		return nil
+	})
+}
+
+// Test_Check tests running validators against the current values.
+func Test_Check(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("MIN_WORKERS", "8")
+	src.SetValue("TLS_CERT", "cert.pem")
+	c := New(WithSource(src))
+	registerTestWorkers(t, c)
+	for _, name := range []string{"TLS_CERT", "TLS_KEY"} {
+		if err := c.NewString(name, "", name); err != nil {
+			t.Fatalf("NewString() = %v; want nil", err)
+		}
+	}
+	c.Validate(func(c Config) error {
+		if (c.String("TLS_CERT") == "") != (c.String("TLS_KEY") == "") {
+			return Violation("must both be set", "TLS_CERT", "TLS_KEY")
+		}
+		return nil
+	})
+	c.Validate(func(c Config) error {
+		return errors.New("plain error")
+	})
+	err := c.Check()
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Check() = %v; want Errors", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("Check() returned %d errors; want 3: %v", len(errs), err)
+	}
+	want := []string{
+		"MIN_WORKERS: invalid argument: must not exceed MAX_WORKERS",
+		"TLS_CERT, TLS_KEY: invalid argument: must both be set",
+		"plain error",
+	}
+	for i, err := range errs {
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("Check()[%d] = %T; want *ValidationError", i, err)
+		}
+		if err.Error() != want[i] {
+			t.Errorf("Check()[%d] = %q; want %q", i, err.Error(), want[i])
+		}
+	}
+	if !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Check() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+}
+
+// Test_Check_Valid tests that Check returns nil when there are no
+// violations, or no validators.
+func Test_Check_Valid(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newTestSource()))
+	if err := c.Check(); err != nil {
+		t.Errorf("Check() = %v; want nil", err)
+	}
+	registerTestWorkers(t, c)
+	if err := c.Check(); err != nil {
+		t.Errorf("Check() = %v; want nil", err)
+	}
+}
+
+// Test_Check_Errors tests that a validator can report several violations.
+func Test_Check_Errors(t *testing.T) {
+	t.Parallel()
+	c := New(WithSource(newTestSource()))
+	c.Validate(func(c Config) error {
+		return Errors{Violation("first", "A"), Violation("second", "B")}
+	})
+	var errs Errors
+	if err := c.Check(); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("Check() = %v; want two errors", err)
+	}
+}
+
+// Test_Validate_Reload tests that validators are run against reloaded values,
+// and that a reload violating them changes nothing.
+func Test_Validate_Reload(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	c := New(WithSource(src))
+	registerTestWorkers(t, c)
+	called := false
+	if err := c.OnChange("MIN_WORKERS", func() { called = true }); err != nil {
+		t.Fatalf("OnChange() = %v; want nil", err)
+	}
+	src.SetValue("MIN_WORKERS", "8")
+	var verr *ValidationError
+	if err := c.Reload(); !errors.As(err, &verr) {
+		t.Fatalf("Reload() = %v; want *ValidationError", err)
+	}
+	if v := c.Int("MIN_WORKERS"); v != 1 {
+		t.Errorf("Int() = %v; want 1", v)
+	}
+	if called {
+		t.Errorf("OnChange callback was called")
+	}
+	src.SetValue("MAX_WORKERS", "16")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	if v := c.Int("MIN_WORKERS"); v != 8 {
+		t.Errorf("Int() = %v; want 8", v)
+	}
+	if !called {
+		t.Errorf("OnChange callback was not called")
+	}
+}
+
+// Test_Validate_Sub tests validators registered through a sub-config.
+func Test_Validate_Sub(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	src.SetValue("POOL_MIN_WORKERS", "8")
+	c := New(WithSource(src))
+	registerTestWorkers(t, c.Sub("POOL"))
+	if err := c.Check(); err == nil {
+		t.Errorf("Check() = nil; want error")
+	}
+	src.SetValue("POOL_MAX_WORKERS", "8")
+	if err := c.Reload(); err != nil {
+		t.Errorf("Reload() = %v; want nil", err)
+	}
+}
+
+// Test_Validate_Snapshot tests that validators run by Reload can read every
+// type of variable.
+func Test_Validate_Snapshot(t *testing.T) {
+	t.Parallel()
+	src := newTestSource()
+	c := New(WithSource(src))
+	for _, err := range []error{
+		c.NewInt("INT", 1, "int"),
+		c.NewString("STRING", "a", "string"),
+		c.NewBool("BOOL", false, "bool"),
+		c.NewDuration("DURATION", time.Second, "duration"),
+		c.NewFloat("FLOAT", 1, "float"),
+		c.NewByteSize("BYTE_SIZE", 1, "byte size"),
+		c.NewStringList("STRING_LIST", nil, "string list"),
+		c.NewIntList("INT_LIST", nil, "int list"),
+		c.NewStringMap("STRING_MAP", nil, "string map"),
+	} {
+		if err != nil {
+			t.Fatalf("New() = %v; want nil", err)
+		}
+	}
+	var got []interface{}
+	c.Validate(func(c Config) error {
+		got = []interface{}{
+			c.Int("INT"), c.String("STRING"), c.Bool("BOOL"), c.Duration("DURATION"), c.Float("FLOAT"),
+			c.ByteSize("BYTE_SIZE"), c.StringList("STRING_LIST"), c.IntList("INT_LIST"), c.StringMap("STRING_MAP"),
+		}
+		return nil
+	})
+	for name, value := range map[string]string{
+		"INT": "2", "STRING": "b", "BOOL": "true", "DURATION": "2s", "FLOAT": "2.5",
+		"BYTE_SIZE": "2Ki", "STRING_LIST": "x,y", "INT_LIST": "3,4", "STRING_MAP": "k=v",
+	} {
+		src.SetValue(name, value)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() = %v; want nil", err)
+	}
+	want := []interface{}{
+		2, "b", true, 2 * time.Second, 2.5,
+		int64(2048), []string{"x", "y"}, []int{3, 4}, map[string]string{"k": "v"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("validator values mismatch (-want +got):\n%s", diff)
+	}
+}
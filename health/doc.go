@@ -0,0 +1,23 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health implements liveness and readiness checks.
+//
+// Components register named checks with a Health. The results of the checks
+// are aggregated into a liveness result, which reports whether the process
+// should be restarted, and a readiness result, which reports whether it
+// should receive traffic. Both are served as JSON by the handlers returned by
+// LivenessHandler and ReadinessHandler, conventionally at /healthz and
+// /readyz.
+package health
@@ -0,0 +1,51 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	ht "net/http"
+)
+
+// LivenessHandler returns an ht.Handler that serves the liveness result as
+// JSON.
+func (h *health) LivenessHandler() ht.Handler {
+	return resultHandler(h.Liveness)
+}
+
+// ReadinessHandler returns an ht.Handler that serves the readiness result
+// as JSON.
+func (h *health) ReadinessHandler() ht.Handler {
+	return resultHandler(h.Readiness)
+}
+
+// resultHandler returns an ht.Handler that serves the result of the given
+// function as JSON. The status code is 503 Service Unavailable if the result
+// is down, and 200 OK otherwise, so that a degraded process keeps receiving
+// traffic.
+func resultHandler(result func(ctx context.Context) Result) ht.Handler {
+	return ht.HandlerFunc(func(w ht.ResponseWriter, r *ht.Request) {
+		res := result(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if res.Status == StatusDown {
+			w.WriteHeader(ht.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(ht.StatusOK)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
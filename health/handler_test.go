@@ -0,0 +1,109 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"encoding/json"
+	ht "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Test_Handler_Case is a test case for Test_Handler.
+type Test_Handler_Case struct {
+	// name is the name of the test case.
+	name string
+	// handler returns the handler to test.
+	handler func(h Health) ht.Handler
+	// ready is passed to SetReady.
+	ready bool
+	// check is the readiness and liveness check.
+	check Check
+	// critical is true if the check is critical.
+	critical bool
+	// code is the expected status code.
+	code int
+	// expected is the expected result.
+	expected Result
+}
+
+// Test_Handler tests serving results as JSON.
+func Test_Handler(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_Handler_Case{
+		{
+			name:     "liveness-up",
+			handler:  Health.LivenessHandler,
+			check:    pass,
+			critical: true,
+			code:     ht.StatusOK,
+			expected: Result{Status: StatusUp, Checks: map[string]CheckResult{"db": {Status: StatusUp, Critical: true}}},
+		},
+		{
+			name:     "liveness-down",
+			handler:  Health.LivenessHandler,
+			check:    fail,
+			critical: true,
+			code:     ht.StatusServiceUnavailable,
+			expected: Result{Status: StatusDown, Checks: map[string]CheckResult{"db": {Status: StatusDown, Critical: true, Error: "connection refused"}}},
+		},
+		{
+			name:     "readiness-degraded",
+			handler:  Health.ReadinessHandler,
+			ready:    true,
+			check:    fail,
+			code:     ht.StatusOK,
+			expected: Result{Status: StatusDegraded, Checks: map[string]CheckResult{"db": {Status: StatusDown, Error: "connection refused"}}},
+		},
+		{
+			name:     "readiness-not-ready",
+			handler:  Health.ReadinessHandler,
+			check:    pass,
+			critical: true,
+			code:     ht.StatusServiceUnavailable,
+			expected: Result{Status: StatusDown, Message: "not ready", Checks: map[string]CheckResult{"db": {Status: StatusUp, Critical: true}}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := New()
+			if err := h.AddLivenessCheck("db", tc.check, WithCritical(tc.critical)); err != nil {
+				t.Fatalf("AddLivenessCheck() = %v; want nil", err)
+			}
+			if err := h.AddReadinessCheck("db", tc.check, WithCritical(tc.critical)); err != nil {
+				t.Fatalf("AddReadinessCheck() = %v; want nil", err)
+			}
+			h.SetReady(tc.ready)
+			rec := httptest.NewRecorder()
+			tc.handler(h).ServeHTTP(rec, httptest.NewRequest(ht.MethodGet, "/", nil))
+			if rec.Code != tc.code {
+				t.Errorf("status code = %v; want %v", rec.Code, tc.code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %v; want application/json", ct)
+			}
+			var got Result
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Unmarshal() = %v; want nil", err)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
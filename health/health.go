@@ -0,0 +1,276 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ht "net/http"
+	"sync"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// DefaultTimeout is the time a check may take before it fails, unless it
+// was registered with WithTimeout.
+const DefaultTimeout = time.Second
+
+// Status is the status of a check, or of a set of checks.
+type Status string
+
+const (
+	// StatusUp means that every check passed.
+	StatusUp Status = "up"
+	// StatusDegraded means that a non-critical check failed.
+	StatusDegraded Status = "degraded"
+	// StatusDown means that a critical check failed.
+	StatusDown Status = "down"
+)
+
+// Check checks the health of a component. It returns nil if the component
+// is healthy. The context is canceled when the timeout of the check expires.
+type Check func(ctx context.Context) error
+
+// Health aggregates the liveness and readiness checks of a service.
+type Health interface {
+	// AddLivenessCheck registers a check that reports whether the process
+	// is alive. A process that is not alive should be restarted. It returns
+	// status.ErrInvalidArgument if check is nil.
+	AddLivenessCheck(name string, check Check, opts ...CheckOption) error
+	// AddReadinessCheck registers a check that reports whether the process
+	// is ready to receive traffic. It returns status.ErrInvalidArgument if
+	// check is nil.
+	AddReadinessCheck(name string, check Check, opts ...CheckOption) error
+	// Liveness runs the liveness checks and returns the result.
+	Liveness(ctx context.Context) Result
+	// Readiness runs the readiness checks and returns the result. The
+	// result is down if the Health is not ready, regardless of the checks.
+	Readiness(ctx context.Context) Result
+	// SetReady sets whether the process is ready to receive traffic. A new
+	// Health is not ready. A service sets it to ready once its workers have
+	// started, and back to not ready when it shuts down.
+	SetReady(ready bool)
+	// LivenessHandler returns an ht.Handler that serves the liveness
+	// result as JSON.
+	LivenessHandler() ht.Handler
+	// ReadinessHandler returns an ht.Handler that serves the readiness
+	// result as JSON.
+	ReadinessHandler() ht.Handler
+}
+
+// Result is the result of a set of checks.
+type Result struct {
+	// Status is StatusDown if a critical check failed or the process is not
+	// ready, StatusDegraded if a non-critical check failed, and StatusUp
+	// otherwise.
+	Status Status `json:"status"`
+	// Message explains why the result is down when no check failed.
+	Message string `json:"message,omitempty"`
+	// Checks holds the result of each check, keyed by name.
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single check.
+type CheckResult struct {
+	// Status is StatusUp if the check passed, and StatusDown otherwise.
+	Status Status `json:"status"`
+	// Critical is true if the failure of the check makes the result down.
+	Critical bool `json:"critical"`
+	// Error is the error returned by the check, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// check is a registered check.
+type check struct {
+	// name is the name of the check.
+	name string
+	// run runs the check.
+	run Check
+	// timeout is the time the check may take.
+	timeout time.Duration
+	// critical is true if the failure of the check makes the result down.
+	critical bool
+}
+
+// CheckOption is an option for a check.
+type CheckOption func(c *check) error
+
+// WithTimeout returns an option that sets the time a check may take before
+// it fails. The default is DefaultTimeout.
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: timeout must be positive", status.ErrOutOfRange)
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithCritical returns an option that sets whether a check is critical.
+// Checks are critical by default. The failure of a non-critical check makes
+// the result degraded instead of down.
+func WithCritical(critical bool) CheckOption {
+	return func(c *check) error {
+		c.critical = critical
+		return nil
+	}
+}
+
+// health is the default implementation of Health.
+type health struct {
+	// lock guards the fields below.
+	lock sync.RWMutex
+	// liveness holds the liveness checks.
+	liveness []*check
+	// readiness holds the readiness checks.
+	readiness []*check
+	// ready is true if the process is ready to receive traffic.
+	ready bool
+}
+
+// New returns a new Health with no checks. It is not ready until SetReady
+// is called.
+func New() Health {
+	return &health{}
+}
+
+// AddLivenessCheck registers a check that reports whether the process is
+// alive.
+func (h *health) AddLivenessCheck(name string, run Check, opts ...CheckOption) error {
+	return h.add(&h.liveness, name, run, opts)
+}
+
+// AddReadinessCheck registers a check that reports whether the process is
+// ready to receive traffic.
+func (h *health) AddReadinessCheck(name string, run Check, opts ...CheckOption) error {
+	return h.add(&h.readiness, name, run, opts)
+}
+
+// add adds a check to a list of checks. It returns status.ErrInvalidArgument
+// if run is nil.
+func (h *health) add(checks *[]*check, name string, run Check, opts []CheckOption) error {
+	if run == nil {
+		return fmt.Errorf("%w: check %s is nil", status.ErrInvalidArgument, name)
+	}
+	c := &check{
+		name:     name,
+		run:      run,
+		timeout:  DefaultTimeout,
+		critical: true,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, other := range *checks {
+		if other.name == name {
+			return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
+		}
+	}
+	*checks = append(*checks, c)
+	return nil
+}
+
+// Liveness runs the liveness checks and returns the result.
+func (h *health) Liveness(ctx context.Context) Result {
+	h.lock.RLock()
+	checks := h.liveness
+	h.lock.RUnlock()
+	return runChecks(ctx, checks)
+}
+
+// Readiness runs the readiness checks and returns the result.
+func (h *health) Readiness(ctx context.Context) Result {
+	h.lock.RLock()
+	checks, ready := h.readiness, h.ready
+	h.lock.RUnlock()
+	result := runChecks(ctx, checks)
+	if !ready {
+		result.Status = StatusDown
+		result.Message = "not ready"
+	}
+	return result
+}
+
+// SetReady sets whether the process is ready to receive traffic.
+func (h *health) SetReady(ready bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.ready = ready
+}
+
+// runChecks runs the checks concurrently and aggregates their results.
+func runChecks(ctx context.Context, checks []*check) Result {
+	result := Result{Status: StatusUp}
+	if len(checks) == 0 {
+		return result
+	}
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.check(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+	result.Checks = make(map[string]CheckResult, len(checks))
+	for i, c := range checks {
+		r := results[i]
+		result.Checks[c.name] = r
+		if r.Status == StatusUp {
+			continue
+		}
+		if r.Critical {
+			result.Status = StatusDown
+		} else if result.Status == StatusUp {
+			result.Status = StatusDegraded
+		}
+	}
+	return result
+}
+
+// check runs the check with its timeout. If the check does not return in
+// time, it fails, and its goroutine is left to finish on its own.
+func (c *check) check(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.run(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %v", c.timeout)
+	}
+	result := CheckResult{Status: StatusUp, Critical: c.critical}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
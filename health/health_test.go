@@ -0,0 +1,181 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// pass is a check that passes.
+func pass(ctx context.Context) error {
+	return nil
+}
+
+// fail is a check that fails.
+func fail(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+// Test_Liveness_Case is a test case for Test_Liveness.
+type Test_Liveness_Case struct {
+	// name is the name of the test case.
+	name string
+	// setup registers the checks.
+	setup func(h Health) error
+	// expected is the expected result.
+	expected Result
+}
+
+// Test_Liveness tests aggregating the results of liveness checks.
+func Test_Liveness(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_Liveness_Case{
+		{
+			name:     "no-checks",
+			setup:    func(h Health) error { return nil },
+			expected: Result{Status: StatusUp},
+		},
+		{
+			name: "pass",
+			setup: func(h Health) error {
+				return h.AddLivenessCheck("loop", pass)
+			},
+			expected: Result{
+				Status: StatusUp,
+				Checks: map[string]CheckResult{"loop": {Status: StatusUp, Critical: true}},
+			},
+		},
+		{
+			name: "critical-failure",
+			setup: func(h Health) error {
+				if err := h.AddLivenessCheck("loop", pass); err != nil {
+					return err
+				}
+				return h.AddLivenessCheck("db", fail)
+			},
+			expected: Result{
+				Status: StatusDown,
+				Checks: map[string]CheckResult{
+					"loop": {Status: StatusUp, Critical: true},
+					"db":   {Status: StatusDown, Critical: true, Error: "connection refused"},
+				},
+			},
+		},
+		{
+			name: "non-critical-failure",
+			setup: func(h Health) error {
+				return h.AddLivenessCheck("cache", fail, WithCritical(false))
+			},
+			expected: Result{
+				Status: StatusDegraded,
+				Checks: map[string]CheckResult{"cache": {Status: StatusDown, Error: "connection refused"}},
+			},
+		},
+		{
+			name: "timeout",
+			setup: func(h Health) error {
+				return h.AddLivenessCheck("slow", func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}, WithTimeout(10*time.Millisecond))
+			},
+			expected: Result{
+				Status: StatusDown,
+				Checks: map[string]CheckResult{"slow": {Status: StatusDown, Critical: true, Error: "timed out after 10ms"}},
+			},
+		},
+		{
+			name: "timeout-ignored",
+			setup: func(h Health) error {
+				return h.AddLivenessCheck("stuck", func(ctx context.Context) error {
+					time.Sleep(200 * time.Millisecond)
+					return nil
+				}, WithTimeout(10*time.Millisecond))
+			},
+			expected: Result{
+				Status: StatusDown,
+				Checks: map[string]CheckResult{"stuck": {Status: StatusDown, Critical: true, Error: "timed out after 10ms"}},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := New()
+			if err := tc.setup(h); err != nil {
+				t.Fatalf("setup() = %v; want nil", err)
+			}
+			if diff := cmp.Diff(tc.expected, h.Liveness(context.Background())); diff != "" {
+				t.Errorf("Liveness() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Test_Readiness tests that readiness is down until the Health is ready.
+func Test_Readiness(t *testing.T) {
+	t.Parallel()
+	h := New()
+	if err := h.AddReadinessCheck("db", pass); err != nil {
+		t.Fatalf("AddReadinessCheck() = %v; want nil", err)
+	}
+	if r := h.Readiness(context.Background()); r.Status != StatusDown || r.Message != "not ready" {
+		t.Errorf("Readiness() = %v; want down, not ready", r)
+	}
+	h.SetReady(true)
+	if r := h.Readiness(context.Background()); r.Status != StatusUp {
+		t.Errorf("Readiness() = %v; want up", r)
+	}
+	if r := h.Liveness(context.Background()); len(r.Checks) != 0 {
+		t.Errorf("Liveness() = %v; want no checks", r)
+	}
+	h.SetReady(false)
+	if r := h.Readiness(context.Background()); r.Status != StatusDown {
+		t.Errorf("Readiness() = %v; want down", r)
+	}
+}
+
+// Test_AddCheck_Errors tests that invalid checks are rejected.
+func Test_AddCheck_Errors(t *testing.T) {
+	t.Parallel()
+	h := New()
+	if err := h.AddReadinessCheck("db", pass); err != nil {
+		t.Fatalf("AddReadinessCheck() = %v; want nil", err)
+	}
+	if err := h.AddReadinessCheck("db", pass); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("AddReadinessCheck() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+	if err := h.AddLivenessCheck("db", pass); err != nil {
+		t.Errorf("AddLivenessCheck() = %v; want nil", err)
+	}
+	if err := h.AddLivenessCheck("slow", pass, WithTimeout(0)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("AddLivenessCheck() = %v; want %v", err, status.ErrOutOfRange)
+	}
+	if err := h.AddLivenessCheck("nil", nil); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("AddLivenessCheck() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := h.AddReadinessCheck("nil", nil); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("AddReadinessCheck() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if r := h.Liveness(context.Background()); len(r.Checks) != 1 {
+		t.Errorf("Liveness() = %v; want only the db check", r)
+	}
+}
@@ -22,7 +22,7 @@ func (s *complete) bugHandler(msg string) {
 	s.Logger().Errorw("BUG", "message", msg, "stack", stack)
 	// If development mode is enabled, we also log the bug to the console
 	// and panic.
-	if s.config != nil && s.config.DevMode() {
+	if s.config != nil && s.config.DevMode {
 		s.Logger().Errorw("BUG", "message", msg, "stack", stack)
 		panic(msg)
 	}
@@ -16,53 +16,34 @@ package complete
 
 import "github.com/neuralnorthwest/mu/config"
 
-const MessageConfigVar = "MESSAGE"
-const DevModeConfigVar = "DEV_MODE"
-
-// Config is a convenience wrapper around the service configuration.
+// Config holds the service configuration. Each tagged field is a
+// configuration variable: the mu tag is the name of the variable, the default
+// tag is its default value, and the desc tag is its description.
 type Config struct {
-	// config is the service configuration.
-	config config.Config
+	// Message is the message to display.
+	Message string `mu:"MESSAGE" default:"Hello, World!" desc:"The message to display."`
+	// DevMode enables development mode. The application will behave slightly
+	// differently in development mode. For instance:
+	//
+	//   - The bug handler will print bugs to the console and panic.
+	DevMode bool `mu:"DEV_MODE" desc:"Enable development mode."`
 }
 
 // setupConfig sets up the service configuration. This is where we register
-// configuration variables.
+// configuration variables. MainCommand also invokes it against a throwaway
+// configuration to discover the command-line flags, so it is invoked more
+// than once; the last invocation is always with the service configuration,
+// so s.config ends up holding the values the service runs with.
 func (s *complete) setupConfig(c config.Config) error {
-	// Register a configuration variable. The first argument is the name of the
-	// variable. The second argument is the default value. The third argument is
-	// the description of the variable.
-	//
-	// It's good practice to use constants for the variable names. This makes it
-	// easier to find all references to a variable, as well as preventing
-	// typos.
-	err := c.NewString(MessageConfigVar, "Hello, World!", "The message to display.")
-	// The NewString, NewInt, and NewBool methods return an error that must be
-	// checked. An error could occur if the variable has already been
-	// registered, the default value is invalid, or the value set in the
+	// Bind registers a configuration variable for each tagged field of the
+	// struct, and sets the fields to the values of the variables. Bind returns
+	// an error that must be checked. An error could occur if a variable has
+	// already been registered, a tag is invalid, or the value set in the
 	// environment is invalid.
-	if err != nil {
+	cfg := &Config{}
+	if err := config.Bind(c, cfg); err != nil {
 		return err
 	}
-	// DEV_MODE enables development mode. The application will behave slightly
-	// differently in development mode. For instance:
-	//
-	//   - The bug handler will print bugs to the console and panic.
-	err = c.NewBool(DevModeConfigVar, false, "Enable development mode.")
-	if err != nil {
-		return err
-	}
-	s.config = &Config{
-		config: c,
-	}
+	s.config = cfg
 	return nil
 }
-
-// Message returns the message to display.
-func (c *Config) Message() string {
-	return c.config.String(MessageConfigVar)
-}
-
-// DevMode returns true if development mode is enabled.
-func (c *Config) DevMode() bool {
-	return c.config.Bool(DevModeConfigVar)
-}
@@ -21,7 +21,7 @@ func (s *complete) helloHandler(w ht.ResponseWriter, r *ht.Request) {
 	// Increment the hello counter.
 	s.metrics.helloCounter.Inc()
 	// Get the configured message.
-	msg := s.config.Message()
+	msg := s.config.Message
 	// Write the message to the response.
 	_, err := w.Write([]byte(msg))
 	if err != nil {
@@ -15,6 +15,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/neuralnorthwest/mu/config"
@@ -62,3 +63,24 @@ func Test_Cleanups(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []int{4, 3, 2, 5, 1}, cleanupOrder)
 }
+
+// Test_Cleanups_WorkerPanic tests that cleanups are run when a worker
+// panics, and that Run returns the panic as an error.
+func Test_Cleanups_WorkerPanic(t *testing.T) {
+	t.Parallel()
+	svc, err := New("test-service")
+	assert.NoError(t, err)
+	cleanedUp := false
+	svc.Cleanup(func() {
+		cleanedUp = true
+	})
+	svc.SetupWorkers(func(wg worker.Group) error {
+		return wg.Add("panicky", testFuncWorker(func(ctx context.Context) error {
+			panic("boom")
+		}))
+	})
+	err = svc.Run()
+	var perr *worker.PanicError
+	assert.ErrorAs(t, err, &perr)
+	assert.True(t, cleanedUp)
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/spf13/cobra"
+)
+
+// configCommand returns the config command, which inspects the configuration
+// of the service without starting it.
+func (s *Service) configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the service configuration",
+		Long: "Inspect the service configuration. The configuration setup hook is run, " +
+			"but no workers are started.",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the resolved configuration, with secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer s.invokeCleanups()
+			err := s.setupConfig()
+			if werr := writeConfigList(cmd.OutOrStdout(), s.config.Variables()); werr != nil {
+				return werr
+			}
+			return reportConfigError(cmd.ErrOrStderr(), err)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration, exiting non-zero if it is invalid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer s.invokeCleanups()
+			if err := s.setupConfig(); err != nil {
+				return reportConfigError(cmd.ErrOrStderr(), err)
+			}
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+			return err
+		},
+	})
+	var format string
+	docs := &cobra.Command{
+		Use:   "docs",
+		Short: "Write reference documentation for the configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer s.invokeCleanups()
+			write, ok := map[string]func(io.Writer, []config.VariableInfo) error{
+				"markdown":    config.WriteMarkdown,
+				"json-schema": config.WriteJSONSchema,
+				"dotenv":      config.WriteDotEnv,
+			}[format]
+			if !ok {
+				return fmt.Errorf("unknown format %q: want markdown, json-schema, or dotenv", format)
+			}
+			// The documentation describes the defaults, so errors in the
+			// values of the variables do not matter here.
+			if err := s.invokeSetupConfig(s.config); err != nil {
+				return reportConfigError(cmd.ErrOrStderr(), err)
+			}
+			return write(cmd.OutOrStdout(), s.config.Variables())
+		},
+	}
+	docs.Flags().StringVar(&format, "format", "markdown", "output format: markdown, json-schema, or dotenv")
+	cmd.AddCommand(docs)
+	return cmd
+}
+
+// writeConfigList writes a table of the values of the variables.
+func writeConfigList(w io.Writer, vars []config.VariableInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tVALUE\tORIGIN")
+	for _, v := range vars {
+		origin := v.Origin
+		if origin == "" {
+			origin = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.Key, v.Type, v.Value, origin)
+	}
+	return tw.Flush()
+}
+
+// reportConfigError writes each configuration error on its own line, and
+// returns err.
+func reportConfigError(w io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		errs = config.Errors{err}
+	}
+	for _, e := range errs {
+		fmt.Fprintf(w, "error: %v\n", e)
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/worker"
+)
+
+// runConfigCommand runs the main command of a service with the given
+// arguments, and returns its output and error. The service registers a port,
+// a secret, and a required variable.
+func runConfigCommand(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		if err := c.NewInt("TEST_CONFIG_COMMAND_PORT", 8080, "Port to listen on.", config.WithMaximumValue(9000)); err != nil {
+			return err
+		}
+		if err := c.NewString("TEST_CONFIG_COMMAND_PASSWORD", "", "Database password.", config.WithSecret()); err != nil {
+			return err
+		}
+		return c.NewString("TEST_CONFIG_COMMAND_DATABASE_URL", "", "Database URL.", config.WithRequired())
+	})
+	svc.SetupWorkers(func(worker.Group) error {
+		t.Errorf("setup workers hook was invoked")
+		return nil
+	})
+	var stdout, stderr bytes.Buffer
+	cmd := svc.MainCommand()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err = cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+// Test_ConfigCommand_List tests that "config list" prints the resolved
+// configuration with secrets redacted.
+func Test_ConfigCommand_List(t *testing.T) {
+	t.Setenv("TEST_CONFIG_COMMAND_PORT", "8081")
+	t.Setenv("TEST_CONFIG_COMMAND_PASSWORD", "hunter2")
+	t.Setenv("TEST_CONFIG_COMMAND_DATABASE_URL", "postgres://db")
+	stdout, _, err := runConfigCommand(t, "config", "list")
+	if err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	for _, want := range []string{"VARIABLE", "TEST_CONFIG_COMMAND_PORT", "8081", "postgres://db", config.Redacted} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output does not contain %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "hunter2") {
+		t.Errorf("output contains the secret:\n%s", stdout)
+	}
+}
+
+// Test_ConfigCommand_List_Flags tests that "config list" reports values set
+// by flags.
+func Test_ConfigCommand_List_Flags(t *testing.T) {
+	t.Setenv("TEST_CONFIG_COMMAND_PORT", "8081")
+	t.Setenv("TEST_CONFIG_COMMAND_DATABASE_URL", "postgres://db")
+	stdout, _, err := runConfigCommand(t, "config", "list", "--test-config-command-port", "8082")
+	if err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	var line string
+	for _, l := range strings.Split(stdout, "\n") {
+		if strings.HasPrefix(l, "TEST_CONFIG_COMMAND_PORT") {
+			line = l
+		}
+	}
+	for _, want := range []string{"8082", "flags"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("output does not contain %q:\n%s", want, stdout)
+		}
+	}
+}
+
+// Test_ConfigCommand_Validate tests that "config validate" succeeds for a
+// valid configuration.
+func Test_ConfigCommand_Validate(t *testing.T) {
+	t.Setenv("TEST_CONFIG_COMMAND_DATABASE_URL", "postgres://db")
+	stdout, _, err := runConfigCommand(t, "config", "validate")
+	if err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	if !strings.Contains(stdout, "configuration is valid") {
+		t.Errorf("output = %q; want configuration is valid", stdout)
+	}
+}
+
+// Test_ConfigCommand_Validate_Invalid tests that "config validate" reports
+// every error for an invalid configuration.
+func Test_ConfigCommand_Validate_Invalid(t *testing.T) {
+	t.Setenv("TEST_CONFIG_COMMAND_PORT", "9001")
+	_, stderr, err := runConfigCommand(t, "config", "validate")
+	if err == nil {
+		t.Fatalf("Execute did not return an error")
+	}
+	for _, want := range []string{"TEST_CONFIG_COMMAND_PORT", "TEST_CONFIG_COMMAND_DATABASE_URL"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("errors do not mention %s:\n%s", want, stderr)
+		}
+	}
+}
+
+// Test_ConfigCommand_Docs tests that "config docs" writes documentation in
+// each format, even when the values are invalid.
+func Test_ConfigCommand_Docs(t *testing.T) {
+	t.Setenv("TEST_CONFIG_COMMAND_PORT", "invalid")
+	for format, want := range map[string]string{
+		"markdown":    "| `TEST_CONFIG_COMMAND_PORT` | int | `8080` |",
+		"json-schema": `"$schema"`,
+		"dotenv":      "TEST_CONFIG_COMMAND_PORT=8080\n",
+	} {
+		stdout, _, err := runConfigCommand(t, "config", "docs", "--format", format)
+		if err != nil {
+			t.Fatalf("Execute returned an error: %v", err)
+		}
+		if !strings.Contains(stdout, want) {
+			t.Errorf("%s output does not contain %q:\n%s", format, want, stdout)
+		}
+	}
+	if _, _, err := runConfigCommand(t, "config", "docs", "--format", "html"); err == nil {
+		t.Errorf("Execute did not return an error")
+	}
+}
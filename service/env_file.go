@@ -0,0 +1,74 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import "github.com/neuralnorthwest/mu/config"
+
+// envFileSource is the configuration source for the dotenv file of the
+// service. The file is not known until the --env-file flag is parsed, so the
+// source starts out empty, and is replaced by loadEnvFile.
+type envFileSource struct {
+	config.Source
+	// prefix is the prefix set with SetPrefix.
+	prefix string
+}
+
+// newEnvFileSource returns an empty envFileSource.
+func newEnvFileSource() *envFileSource {
+	return &envFileSource{Source: config.NewChainSource()}
+}
+
+// SetPrefix sets the prefix for the names of the variables.
+func (s *envFileSource) SetPrefix(prefix string) {
+	s.prefix = prefix
+	s.Source.SetPrefix(prefix)
+}
+
+// Reload reloads the dotenv file, if there is one.
+func (s *envFileSource) Reload() error {
+	if r, ok := s.Source.(config.Reloader); ok {
+		return r.Reload()
+	}
+	return nil
+}
+
+// Stage stages the dotenv file, if there is one.
+func (s *envFileSource) Stage() (config.Source, func(), error) {
+	if st, ok := s.Source.(config.Stager); ok {
+		return st.Stage()
+	}
+	return s.Source, func() {}, nil
+}
+
+// set replaces the source.
+func (s *envFileSource) set(src config.Source) {
+	src.SetPrefix(s.prefix)
+	s.Source = src
+}
+
+// loadEnvFile loads the dotenv file set with WithEnvFile or the --env-file
+// flag, unless it is already loaded.
+func (s *Service) loadEnvFile() error {
+	if s.envFile == "" || s.envFile == s.envFileLoaded {
+		return nil
+	}
+	src, err := config.NewDotEnvSource(s.envFile)
+	if err != nil {
+		return err
+	}
+	s.envFileSource.set(src)
+	s.envFileLoaded = s.envFile
+	return nil
+}
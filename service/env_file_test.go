@@ -0,0 +1,126 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/worker"
+)
+
+// writeEnvFile writes a dotenv file and returns its path.
+func writeEnvFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile returned an error: %v", err)
+	}
+	return path
+}
+
+// Test_Service_New_WithEnvFile tests that WithEnvFile loads variables from a
+// dotenv file, beneath the environment.
+func Test_Service_New_WithEnvFile(t *testing.T) {
+	t.Setenv("TEST_ENV_FILE_MESSAGE", "from env")
+	path := writeEnvFile(t, "export TEST_ENV_FILE_MESSAGE=from file\nTEST_ENV_FILE_PORT=9090\n")
+	svc, err := New("test-service", WithEnvFile(path))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := svc.Config().NewString("TEST_ENV_FILE_MESSAGE", "default", "message"); err != nil {
+		t.Fatalf("NewString returned an error: %v", err)
+	}
+	if err := svc.Config().NewInt("TEST_ENV_FILE_PORT", 8080, "port"); err != nil {
+		t.Fatalf("NewInt returned an error: %v", err)
+	}
+	if v := svc.Config().String("TEST_ENV_FILE_MESSAGE"); v != "from env" {
+		t.Errorf("unexpected message: %s, expected: %s", v, "from env")
+	}
+	if v := svc.Config().Int("TEST_ENV_FILE_PORT"); v != 9090 {
+		t.Errorf("unexpected port: %d, expected: %d", v, 9090)
+	}
+}
+
+// Test_Service_New_WithEnvFile_ConfigSource tests that the environment takes
+// precedence over the configuration source, which takes precedence over the
+// dotenv file.
+func Test_Service_New_WithEnvFile_ConfigSource(t *testing.T) {
+	t.Setenv("TEST_ENV_LAYERS_ENV", "from env")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("TEST_ENV_LAYERS_ENV: from source\nTEST_ENV_LAYERS_SOURCE: from source\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile returned an error: %v", err)
+	}
+	src, err := config.NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource returned an error: %v", err)
+	}
+	envFile := writeEnvFile(t, "TEST_ENV_LAYERS_ENV=from dotenv\nTEST_ENV_LAYERS_SOURCE=from dotenv\nTEST_ENV_LAYERS_DOTENV=from dotenv\n")
+	svc, err := New("test-service", WithConfigSource(src), WithEnvFile(envFile))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	for name, want := range map[string]string{
+		"TEST_ENV_LAYERS_ENV":    "from env",
+		"TEST_ENV_LAYERS_SOURCE": "from source",
+		"TEST_ENV_LAYERS_DOTENV": "from dotenv",
+	} {
+		if err := svc.Config().NewString(name, "default", "message"); err != nil {
+			t.Fatalf("NewString returned an error: %v", err)
+		}
+		if v := svc.Config().String(name); v != want {
+			t.Errorf("unexpected %s: %s, expected: %s", name, v, want)
+		}
+	}
+}
+
+// Test_Service_New_WithEnvFile_Missing tests that New fails if the dotenv
+// file does not exist.
+func Test_Service_New_WithEnvFile_Missing(t *testing.T) {
+	t.Parallel()
+	_, err := New("test-service", WithEnvFile(filepath.Join(t.TempDir(), "missing.env")))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("New returned %v; want %v", err, os.ErrNotExist)
+	}
+}
+
+// Test_MainCommand_EnvFile tests the --env-file flag.
+func Test_MainCommand_EnvFile(t *testing.T) {
+	t.Parallel()
+	path := writeEnvFile(t, "TEST_MAIN_ENV_FILE_NAME=file\nTEST_MAIN_ENV_FILE_MESSAGE=\"from ${TEST_MAIN_ENV_FILE_NAME}\"\n")
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		return c.NewString("TEST_MAIN_ENV_FILE_MESSAGE", "default", "The message to print.")
+	})
+	var message string
+	svc.SetupWorkers(func(group worker.Group) error {
+		message = svc.Config().String("TEST_MAIN_ENV_FILE_MESSAGE")
+		return nil
+	})
+	cmd := svc.MainCommand()
+	cmd.SetArgs([]string{"--env-file", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	if message != "from file" {
+		t.Errorf("unexpected message: %q, expected: %q", message, "from file")
+	}
+}
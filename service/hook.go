@@ -24,6 +24,16 @@ import (
 type PreRunFunc func() error
 
 // SetupConfigFunc is a function that sets up a service configuration.
+//
+// The function may be invoked more than once, and not only with the service
+// configuration: MainCommand invokes it when it builds the command, against
+// a throwaway configuration that loads no values, to discover the variables
+// that need command-line flags. An error or panic from that invocation is
+// logged as a warning. When the command runs, the function is invoked again
+// with the service configuration, so a side effect that each invocation
+// overwrites, such as storing a struct filled by config.Bind, ends up
+// reflecting the service configuration. Other side effects should be
+// avoided.
 type SetupConfigFunc func(c config.Config) error
 
 // SetupWorkersFunc is a function that sets up workers for the service.
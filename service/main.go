@@ -14,11 +14,40 @@
 
 package service
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/status"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
 
 // MainCommand returns the main cobra.Command for the service. This allows you
 // to customize the command (perhaps adding flags) before invoking it with
 // Execute.
+//
+// The command has a config subcommand that runs the configuration setup hook
+// without starting the service: "config list" prints the resolved
+// configuration with secrets redacted, "config validate" exits non-zero if
+// the configuration is invalid, and "config docs" writes reference
+// documentation for the configuration.
+//
+// The command has a flag for each configuration variable, named by
+// config.FlagName (for example, --message for MESSAGE and --dev-mode for
+// DEV_MODE). A flag given on the command line takes precedence over the
+// environment and the configuration source. The --env-file flag loads
+// variables from a dotenv file, beneath the environment. A variable whose
+// flag would collide with a flag of the command or its subcommands, such as
+// --help, --mock, or --env-file, is reported as a configuration error when
+// the command runs.
+//
+// To discover the variables, MainCommand invokes the configuration setup hook
+// against a throwaway config that loads no values, before the arguments are
+// parsed; the hook is invoked again with the service config when the command
+// runs (see SetupConfigFunc). If the discovery run returns an error or
+// panics, a warning is logged, and variables the hook did not register have
+// no flags.
 func (s *Service) MainCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   s.name,
@@ -33,9 +62,74 @@ func (s *Service) MainCommand() *cobra.Command {
 	// Default the mock flag to the current value of s.mockMode. This prevents
 	// the flag's default setting from overriding the value set by WithMockMode.
 	cmd.PersistentFlags().BoolVar(&s.mockMode, "mock", s.mockMode, "enable mock mode")
+	cmd.PersistentFlags().StringVar(&s.envFile, "env-file", s.envFile, "load configuration variables from a dotenv file")
+	cmd.AddCommand(s.configCommand())
+	s.addConfigFlags(cmd)
 	return cmd
 }
 
+// addConfigFlags defines a flag for each configuration variable, and adds the
+// flags to cmd. Variables whose flag would collide with a flag of cmd or of
+// one of its subcommands are left out, and recorded in s.flagErrs.
+func (s *Service) addConfigFlags(cmd *cobra.Command) {
+	reserved := reservedFlags(cmd)
+	s.flagErrs = nil
+	var vars []config.VariableInfo
+	for _, v := range s.configVariables() {
+		if name := config.FlagName(v.Name); !v.Secret && reserved[name] {
+			s.flagErrs = append(s.flagErrs, fmt.Errorf("%w: %s: flag --%s is reserved by the command", status.ErrAlreadyExists, v.Name, name))
+			continue
+		}
+		vars = append(vars, v)
+	}
+	config.AddFlags(s.flags, vars)
+	cmd.PersistentFlags().AddFlagSet(s.flags)
+}
+
+// reservedFlags returns the names of the flags of cmd and its subcommands,
+// including the help flags that cobra adds.
+func reservedFlags(cmd *cobra.Command) map[string]bool {
+	names := make(map[string]bool)
+	add := func(f *pflag.Flag) {
+		names[f.Name] = true
+	}
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.InitDefaultHelpFlag()
+		c.Flags().VisitAll(add)
+		c.PersistentFlags().VisitAll(add)
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(cmd)
+	return names
+}
+
+// configVariables returns the configuration variables of the service: those
+// already registered with the service config, and those registered by the
+// configuration setup hook. An error or panic from the hook does not prevent
+// the command from being built; it is logged as a warning, and reported
+// again when the hook is invoked with the service config.
+func (s *Service) configVariables() []config.VariableInfo {
+	c := config.New(config.WithSource(config.NewChainSource()), config.WithCollectErrors())
+	if err := s.discoverConfig(c); err != nil {
+		s.logger.Warnw("configuration setup hook failed while discovering command-line flags", "error", err)
+	}
+	return append(s.config.Variables(), c.Variables()...)
+}
+
+// discoverConfig invokes the configuration setup hook with c, returning a
+// panic as an error.
+func (s *Service) discoverConfig(c config.Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("configuration setup hook panicked: %v", r)
+		}
+	}()
+	return s.invokeSetupConfig(c)
+}
+
 // Main invokes the main cobra.Command for the service. If you need to customize
 // the command, see MainCommand.
 func (s *Service) Main() error {
@@ -16,9 +16,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/neuralnorthwest/mu/config"
 	"github.com/neuralnorthwest/mu/logging"
+	mock_logging "github.com/neuralnorthwest/mu/logging/mock"
+	"github.com/neuralnorthwest/mu/status"
 	"github.com/neuralnorthwest/mu/worker"
 )
 
@@ -51,3 +56,132 @@ func Test_Main(t *testing.T) {
 		t.Fatal("worker was not run")
 	}
 }
+
+// runMainCommandFlags runs the main command of a service with the given
+// arguments, and returns the values of its MESSAGE and DEV_MODE variables.
+func runMainCommandFlags(t *testing.T, args ...string) (string, bool) {
+	t.Helper()
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		if err := c.NewString("TEST_MAIN_FLAGS_MESSAGE", "default", "The message to print."); err != nil {
+			return err
+		}
+		return c.NewBool("TEST_MAIN_FLAGS_DEV_MODE", false, "Enable development mode.")
+	})
+	var message string
+	var devMode bool
+	svc.SetupWorkers(func(group worker.Group) error {
+		message = svc.Config().String("TEST_MAIN_FLAGS_MESSAGE")
+		devMode = svc.Config().Bool("TEST_MAIN_FLAGS_DEV_MODE")
+		return nil
+	})
+	cmd := svc.MainCommand()
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	return message, devMode
+}
+
+// Test_MainCommand_Flags tests that the main command has a flag for each
+// configuration variable, and that flags take precedence over the
+// environment.
+func Test_MainCommand_Flags(t *testing.T) {
+	t.Setenv("TEST_MAIN_FLAGS_MESSAGE", "from env")
+	message, devMode := runMainCommandFlags(t, "--test-main-flags-message", "from flags", "--test-main-flags-dev-mode")
+	if message != "from flags" {
+		t.Errorf("unexpected message: %s, expected: %s", message, "from flags")
+	}
+	if !devMode {
+		t.Error("dev mode was not enabled")
+	}
+}
+
+// Test_MainCommand_Flags_Unset tests that variables without a flag on the
+// command line are loaded from the environment.
+func Test_MainCommand_Flags_Unset(t *testing.T) {
+	t.Setenv("TEST_MAIN_FLAGS_MESSAGE", "from env")
+	message, devMode := runMainCommandFlags(t)
+	if message != "from env" {
+		t.Errorf("unexpected message: %s, expected: %s", message, "from env")
+	}
+	if devMode {
+		t.Error("dev mode was enabled")
+	}
+}
+
+// Test_MainCommand_SetupConfigPanic tests that a panic in the configuration
+// setup hook, while MainCommand discovers the variables, does not prevent the
+// command from being built.
+func Test_MainCommand_SetupConfigPanic(t *testing.T) {
+	t.Parallel()
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	calls := 0
+	svc.SetupConfig(func(c config.Config) error {
+		calls++
+		if calls == 1 {
+			panic("not ready")
+		}
+		return nil
+	})
+	cmd := svc.MainCommand()
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected number of hook calls: %d, expected: %d", calls, 2)
+	}
+}
+
+// Test_MainCommand_SetupConfigError tests that an error from the
+// configuration setup hook, while MainCommand discovers the variables, is
+// logged as a warning.
+func Test_MainCommand_SetupConfigError(t *testing.T) {
+	t.Parallel()
+	mc := gomock.NewController(t)
+	logger := mock_logging.NewMockLogger(mc)
+	hookErr := errors.New("not ready")
+	logger.EXPECT().Warnw("configuration setup hook failed while discovering command-line flags", "error", hookErr)
+	svc, err := New("test-service", WithLogger(func() (logging.Logger, error) {
+		return logger, nil
+	}))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		return hookErr
+	})
+	svc.MainCommand()
+}
+
+// Test_MainCommand_ReservedFlags tests that a configuration variable whose
+// flag would collide with a flag of the main command or its subcommands is
+// reported as a configuration error.
+func Test_MainCommand_ReservedFlags(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"HELP", "MOCK", "ENV_FILE", "FORMAT"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			svc, err := New("test-service")
+			if err != nil {
+				t.Fatalf("New returned an error: %v", err)
+			}
+			svc.SetupConfig(func(c config.Config) error {
+				return c.NewString(name, "", "A variable named like a flag.")
+			})
+			cmd := svc.MainCommand()
+			cmd.SetArgs(nil)
+			if err := cmd.Execute(); !errors.Is(err, status.ErrAlreadyExists) {
+				t.Errorf("Execute returned %v; want %v", err, status.ErrAlreadyExists)
+			}
+		})
+	}
+}
@@ -15,6 +15,10 @@
 package service
 
 import (
+	"time"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/http"
 	"github.com/neuralnorthwest/mu/logging"
 	"github.com/neuralnorthwest/mu/status"
 	"golang.org/x/mod/semver"
@@ -49,3 +53,69 @@ func WithLogger(newLogger func() (logging.Logger, error)) Option {
 		return nil
 	}
 }
+
+// WithConfigSource returns an option that adds a source of the service
+// configuration variables, such as a configuration file. The source is
+// layered beneath the environment, so that command-line flags and
+// environment variables take precedence over it, and above the dotenv file
+// set with WithEnvFile.
+func WithConfigSource(source config.Source) Option {
+	return func(s *Service) error {
+		s.configSource = source
+		return nil
+	}
+}
+
+// WithEnvFile returns an option that loads configuration variables from a
+// dotenv file, such as a .env file used for local development (see
+// config.NewDotEnvSource). The file is layered beneath the environment, so
+// variables set in the environment take precedence. The --env-file flag of
+// MainCommand overrides the path.
+func WithEnvFile(path string) Option {
+	return func(s *Service) error {
+		s.envFile = path
+		return nil
+	}
+}
+
+// WithConfigReload returns an option that enables configuration reloading.
+// When enabled, the service reloads its configuration when it receives
+// SIGHUP, and whenever the configuration source reports a change (see
+// config.Watcher). Reloads are validated before they are applied; a reload
+// that fails validation is logged and the current values are kept. Use
+// config.Config.OnChange to react to changes.
+func WithConfigReload() Option {
+	return func(s *Service) error {
+		s.configReload = true
+		return nil
+	}
+}
+
+// WithHealthServer returns an option that serves the health endpoints of the
+// service on their own HTTP server: /healthz serves the liveness result, and
+// /readyz serves the readiness result (see Service.Health). The server
+// listens on :8081 unless the options set another address.
+func WithHealthServer(opts ...http.ServerOption) Option {
+	return func(s *Service) error {
+		s.healthServer = true
+		s.healthServerOpts = opts
+		return nil
+	}
+}
+
+// WithShutdownTimeout returns an option that limits the time allowed for the
+// workers to stop when the service shuts down. Workers are stopped one at a
+// time, in the reverse of the order in which they were started. When the
+// timeout expires, the workers that have not stopped are logged, and Run
+// returns an error that wraps context.DeadlineExceeded. By default, there is
+// no limit. To limit the time allowed for a single worker, use
+// worker.WithStopTimeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Service) error {
+		if timeout < 0 {
+			return status.ErrOutOfRange
+		}
+		s.shutdownTimeout = timeout
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/logging"
+	"github.com/neuralnorthwest/mu/status"
+	"github.com/neuralnorthwest/mu/worker"
+)
+
+// configReloader is a worker that reloads the service configuration when the
+// service receives SIGHUP, or when the configuration source reports a change.
+type configReloader struct {
+	// config is the configuration to reload.
+	config config.Config
+	// hupChan is the channel for SIGHUP signals.
+	hupChan chan os.Signal
+}
+
+var _ worker.Worker = (*configReloader)(nil)
+
+// Run implements worker.Worker.
+func (r *configReloader) Run(ctx context.Context, logger logging.Logger) error {
+	signal.Notify(r.hupChan, syscall.SIGHUP)
+	defer signal.Stop(r.hupChan)
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		err := r.config.Watch(ctx, func(err error) {
+			logReload(logger, err)
+		})
+		if err != nil && !errors.Is(err, status.ErrNotImplemented) {
+			logger.Errorw("configuration watch failed", "err", err)
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			<-watchDone
+			return nil
+		case <-r.hupChan:
+			logger.Infow("received SIGHUP, reloading configuration")
+			logReload(logger, r.config.Reload())
+		}
+	}
+}
+
+// logReload logs the result of a configuration reload.
+func logReload(logger logging.Logger, err error) {
+	if err != nil {
+		logger.Errorw("configuration reload failed, keeping current values", "err", err)
+		return
+	}
+	logger.Infow("configuration reloaded")
+}
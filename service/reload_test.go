@@ -0,0 +1,94 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/logging"
+	"github.com/neuralnorthwest/mu/worker"
+)
+
+// testFuncWorker is a worker that runs a function.
+type testFuncWorker func(ctx context.Context) error
+
+// Run implements the Worker interface.
+func (w testFuncWorker) Run(ctx context.Context, logger logging.Logger) error {
+	return w(ctx)
+}
+
+// Test_run_ConfigReload tests that the service reloads its configuration on
+// SIGHUP, and keeps the current values when a reload is invalid.
+func Test_run_ConfigReload(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile returned an error: %v", err)
+		}
+	}
+	writeFile("PORT: 1\n")
+	src, err := config.NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource returned an error: %v", err)
+	}
+	svc, err := New("test-service", WithConfigSource(src), WithConfigReload())
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	changed := make(chan int, 1)
+	svc.SetupConfig(func(c config.Config) error {
+		if err := c.NewInt("PORT", 0, "port", config.WithMaximumValue(10)); err != nil {
+			return err
+		}
+		return c.OnChange("PORT", func() { changed <- c.Int("PORT") })
+	})
+	svc.SetupWorkers(func(group worker.Group) error {
+		return group.Add("reload-test", testFuncWorker(func(ctx context.Context) error {
+			defer svc.Cancel()
+			writeFile("PORT: 2\n")
+			svc.hupChan <- syscall.SIGHUP
+			select {
+			case v := <-changed:
+				if v != 2 {
+					t.Errorf("unexpected PORT after reload: %d, expected: %d", v, 2)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("timed out waiting for reload")
+				return nil
+			}
+			writeFile("PORT: 11\n")
+			svc.hupChan <- syscall.SIGHUP
+			select {
+			case v := <-changed:
+				t.Errorf("unexpected change after invalid reload: %d", v)
+			case <-time.After(100 * time.Millisecond):
+			}
+			if v := svc.Config().Int("PORT"); v != 2 {
+				t.Errorf("unexpected PORT after invalid reload: %d, expected: %d", v, 2)
+			}
+			return nil
+		}))
+	})
+	if err := svc.Run(); err != nil {
+		t.Errorf("Run returned an error: %v", err)
+	}
+}
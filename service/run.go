@@ -16,9 +16,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
 	"syscall"
 
+	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/http"
 	"github.com/neuralnorthwest/mu/logging"
 	"github.com/neuralnorthwest/mu/worker"
 )
@@ -31,10 +34,10 @@ func (s *Service) Run() (status error) {
 	}
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	defer s.cancel()
-	if err := s.invokeSetupConfig(s.config); err != nil {
+	if err := s.setupConfig(); err != nil {
 		return err
 	}
-	workerGroup := worker.NewGroup()
+	workerGroup := worker.NewGroup(worker.WithShutdownTimeout(s.shutdownTimeout))
 	if err := s.invokeSetupWorkers(workerGroup); err != nil {
 		return err
 	}
@@ -45,17 +48,83 @@ func (s *Service) Run() (status error) {
 			return err
 		}
 	}
+	if s.healthServer {
+		healthServer, err := s.newHealthServer()
+		if err != nil {
+			return err
+		}
+		if err := workerGroup.Add("health_server", healthServer); err != nil {
+			return err
+		}
+	}
+	if s.configReload {
+		reloader := &configReloader{config: s.config, hupChan: s.hupChan}
+		if err := workerGroup.Add("config_reloader", reloader); err != nil {
+			return err
+		}
+	}
 	s.startInterruptListener(s.ctx, s.logger, s.cancel)
 	if err := s.invokePreRun(); err != nil {
 		return err
 	}
-	werr := workerGroup.Run(s.ctx, s.logger)
+	if err := workerGroup.Start(s.ctx, s.logger); err != nil {
+		return err
+	}
+	s.health.SetReady(true)
+	go func() {
+		<-s.ctx.Done()
+		s.health.SetReady(false)
+	}()
+	werr := workerGroup.Wait()
+	s.health.SetReady(false)
 	if werr != nil {
 		return werr
 	}
 	return
 }
 
+// newHealthServer returns an HTTP server for the health endpoints.
+func (s *Service) newHealthServer() (*http.Server, error) {
+	opts := append([]http.ServerOption{http.WithAddress(":8081")}, s.healthServerOpts...)
+	server, err := http.NewServer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	server.Handle("/healthz", s.health.LivenessHandler())
+	server.Handle("/readyz", s.health.ReadinessHandler())
+	return server, nil
+}
+
+// setupConfig invokes the setup configuration hook. Every variable whose value
+// is missing or invalid is reported in a single error, along with any error
+// returned by the hook. Variables rejected by MainCommand because their flag
+// is reserved are reported before the hook is invoked. If there are no such errors, the validators registered
+// with config.Config.Validate are run, and every violation is reported in a
+// single error.
+func (s *Service) setupConfig() error {
+	if err := s.loadEnvFile(); err != nil {
+		return err
+	}
+	if len(s.flagErrs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", append(config.Errors{}, s.flagErrs...))
+	}
+	err := s.invokeSetupConfig(s.config)
+	cerr := s.config.Err()
+	if err == nil && cerr == nil {
+		// Cross-variable validators are only run once every variable has
+		// been registered with a valid value.
+		cerr = s.config.Check()
+	}
+	if cerr == nil {
+		return err
+	}
+	errs := cerr.(config.Errors)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("invalid configuration: %w", errs)
+}
+
 // startInterruptListener starts the interrupt listener. This registers a
 // listener for SIGINT and SIGTERM signals, and starts a goroutine that
 // cancels the context when a signal is received.
@@ -16,12 +16,18 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	ht "net/http"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/health"
 	"github.com/neuralnorthwest/mu/http"
 	"github.com/neuralnorthwest/mu/logging"
 	mock_logging "github.com/neuralnorthwest/mu/logging/mock"
@@ -194,6 +200,226 @@ func Test_run_Hooks(t *testing.T) {
 	}
 }
 
+// Test_run_ConfigErrors tests that Run reports every missing or invalid
+// configuration variable in a single error.
+func Test_run_ConfigErrors(t *testing.T) {
+	t.Setenv("TEST_RUN_CONFIG_ERRORS_PORT", "abc")
+	t.Setenv("TEST_RUN_CONFIG_ERRORS_MODE", "standby")
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	setupErr := fmt.Errorf("setup error")
+	svc.SetupConfig(func(c config.Config) error {
+		if err := c.NewString("TEST_RUN_CONFIG_ERRORS_DATABASE_URL", "", "database url", config.WithRequired()); err != nil {
+			return err
+		}
+		if err := c.NewInt("TEST_RUN_CONFIG_ERRORS_PORT", 80, "port", config.WithRequired()); err != nil {
+			return err
+		}
+		if err := c.NewString("TEST_RUN_CONFIG_ERRORS_MODE", "primary", "mode", config.WithAllowedValues("primary", "replica")); err != nil {
+			return err
+		}
+		if err := c.NewBool("TEST_RUN_CONFIG_ERRORS_DEBUG", false, "debug"); err != nil {
+			return err
+		}
+		return setupErr
+	})
+	setupWorkersWasInvoked := false
+	svc.SetupWorkers(func(worker.Group) error {
+		setupWorkersWasInvoked = true
+		return nil
+	})
+	err = svc.Run()
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Run returned %v; want config.Errors", err)
+	}
+	if len(errs) != 4 {
+		t.Errorf("Run returned %d errors; want 4: %v", len(errs), err)
+	}
+	for _, target := range []error{status.ErrNotFound, status.ErrInvalidArgument, setupErr} {
+		if !errors.Is(err, target) {
+			t.Errorf("Run returned %v; want %v", err, target)
+		}
+	}
+	for _, name := range []string{"DATABASE_URL", "PORT", "MODE"} {
+		if !strings.Contains(err.Error(), "TEST_RUN_CONFIG_ERRORS_"+name) {
+			t.Errorf("Run returned %v; want error for %s", err, name)
+		}
+	}
+	if setupWorkersWasInvoked {
+		t.Errorf("setup hook was invoked")
+	}
+}
+
+// Test_run_ConfigValidators tests that Run reports every violation of the
+// configuration validators in a single error.
+func Test_run_ConfigValidators(t *testing.T) {
+	t.Setenv("TEST_RUN_CONFIG_VALIDATORS_MIN_WORKERS", "8")
+	t.Setenv("TEST_RUN_CONFIG_VALIDATORS_TLS_CERT", "cert.pem")
+	svc, err := New("test-service")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		c = c.Sub("TEST_RUN_CONFIG_VALIDATORS")
+		if err := c.NewInt("MIN_WORKERS", 1, "minimum workers"); err != nil {
+			return err
+		}
+		if err := c.NewInt("MAX_WORKERS", 4, "maximum workers"); err != nil {
+			return err
+		}
+		if err := c.NewString("TLS_CERT", "", "certificate file"); err != nil {
+			return err
+		}
+		if err := c.NewString("TLS_KEY", "", "key file"); err != nil {
+			return err
+		}
+		c.Validate(func(c config.Config) error {
+			if c.Int("MIN_WORKERS") > c.Int("MAX_WORKERS") {
+				return config.Violation("must not exceed MAX_WORKERS", "MIN_WORKERS")
+			}
+			return nil
+		})
+		c.Validate(func(c config.Config) error {
+			if (c.String("TLS_CERT") == "") != (c.String("TLS_KEY") == "") {
+				return config.Violation("must both be set", "TLS_CERT", "TLS_KEY")
+			}
+			return nil
+		})
+		return nil
+	})
+	setupWorkersWasInvoked := false
+	svc.SetupWorkers(func(worker.Group) error {
+		setupWorkersWasInvoked = true
+		return nil
+	})
+	err = svc.Run()
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Run returned %v; want config.Errors", err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Run returned %d errors; want 2: %v", len(errs), err)
+	}
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("Run returned %v; want *config.ValidationError", err)
+	}
+	if setupWorkersWasInvoked {
+		t.Errorf("setup hook was invoked")
+	}
+}
+
+// Test_run_DeprecatedConfig tests that a warning is logged when a
+// configuration variable is loaded from a deprecated name.
+func Test_run_DeprecatedConfig(t *testing.T) {
+	t.Setenv("TEST_RUN_DEPRECATED_CONFIG_OLD_PORT", "9090")
+	mc := gomock.NewController(t)
+	logger := mock_logging.NewMockLogger(mc)
+	logger.EXPECT().Info("running in mock mode")
+	logger.EXPECT().Warnw("configuration variable is deprecated",
+		"name", "TEST_RUN_DEPRECATED_CONFIG_OLD_PORT", "replacement", "TEST_RUN_DEPRECATED_CONFIG_PORT")
+	svc, err := New("test-service", WithLogger(func() (logging.Logger, error) {
+		return logger, nil
+	}), WithMockMode())
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	svc.SetupConfig(func(c config.Config) error {
+		return c.NewInt("TEST_RUN_DEPRECATED_CONFIG_PORT", 8080, "port",
+			config.WithDeprecatedName("TEST_RUN_DEPRECATED_CONFIG_OLD_PORT"))
+	})
+	if err := svc.Run(); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if v := svc.Config().Int("TEST_RUN_DEPRECATED_CONFIG_PORT"); v != 9090 {
+		t.Errorf("unexpected port: %d, expected: %d", v, 9090)
+	}
+}
+
+// getStatus returns the status code of a GET request to the given URL, or 0
+// if the request fails. Connections are not kept alive, so that they do not
+// delay the shutdown of the server.
+func getStatus(url string) int {
+	client := &ht.Client{Transport: &ht.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// Test_run_HealthServer tests that the health server serves the health
+// endpoints, and that the service is ready only while its workers run.
+func Test_run_HealthServer(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned an error: %v", err)
+	}
+	svc, err := New("test-service", WithHealthServer(http.WithListener(listener)))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := svc.Health().AddReadinessCheck("db", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("AddReadinessCheck returned an error: %v", err)
+	}
+	if r := svc.Health().Readiness(context.Background()); r.Status != health.StatusDown {
+		t.Errorf("service is ready before Run")
+	}
+	url := "http://" + listener.Addr().String()
+	var liveness, readiness int
+	svc.SetupWorkers(func(group worker.Group) error {
+		return group.Add("probe", testFuncWorker(func(ctx context.Context) error {
+			defer svc.Cancel()
+			deadline := time.Now().Add(5 * time.Second)
+			for readiness != ht.StatusOK && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+				readiness = getStatus(url + "/readyz")
+			}
+			liveness = getStatus(url + "/healthz")
+			return nil
+		}))
+	})
+	if err := svc.Run(); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if readiness != ht.StatusOK {
+		t.Errorf("unexpected /readyz status: %d, expected: %d", readiness, ht.StatusOK)
+	}
+	if liveness != ht.StatusOK {
+		t.Errorf("unexpected /healthz status: %d, expected: %d", liveness, ht.StatusOK)
+	}
+	if r := svc.Health().Readiness(context.Background()); r.Status != health.StatusDown {
+		t.Errorf("service is ready after Run")
+	}
+}
+
+// Test_run_ShutdownTimeout tests that Run returns an error when a worker does
+// not stop within the shutdown timeout.
+func Test_run_ShutdownTimeout(t *testing.T) {
+	t.Parallel()
+	svc, err := New("test-service", WithShutdownTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	svc.SetupWorkers(func(group worker.Group) error {
+		return group.Add("stuck", testFuncWorker(func(ctx context.Context) error {
+			svc.Cancel()
+			<-release
+			return nil
+		}))
+	})
+	if err := svc.Run(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
 // Test_SetupHTTP_Conflict tests that SetupHTTP returns an error if there is
 // already a worker named "http_server" in the worker group.
 func Test_SetupHTTP_Conflict(t *testing.T) {
@@ -291,7 +517,8 @@ func Test_run_Workers(t *testing.T) {
 			matchedErr := false
 			expectAnError := false
 			for _, workerErr := range testCase.workerErrs {
-				if err == workerErr {
+				// Errors returned by several workers are combined.
+				if err == workerErr || (workerErr != nil && errors.Is(err, workerErr)) {
 					matchedErr = true
 				}
 				if workerErr != nil {
@@ -17,9 +17,13 @@ package service
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/neuralnorthwest/mu/config"
+	"github.com/neuralnorthwest/mu/health"
+	"github.com/neuralnorthwest/mu/http"
 	"github.com/neuralnorthwest/mu/logging"
+	"github.com/spf13/pflag"
 )
 
 // Service represents a service.
@@ -38,37 +42,76 @@ type Service struct {
 	cancel context.CancelFunc
 	// config is the config for the service.
 	config config.Config
+	// configSource is an additional source of the configuration variables,
+	// beneath the environment and above the dotenv file, or nil for none.
+	configSource config.Source
+	// envFile is the path of the dotenv file holding configuration
+	// variables, or "" for none.
+	envFile string
+	// envFileLoaded is the path of the dotenv file loaded into
+	// envFileSource.
+	envFileLoaded string
+	// envFileSource is the configuration source for the dotenv file.
+	envFileSource *envFileSource
+	// flags holds a flag for each configuration variable. MainCommand defines
+	// the flags and adds them to the main command.
+	flags *pflag.FlagSet
+	// flagErrs holds an error for each configuration variable whose flag
+	// would collide with a flag of the main command. The errors are reported
+	// when the configuration is set up.
+	flagErrs config.Errors
 	// mockMode is true if the service is in mock mode.
 	mockMode bool
 	// newLogger is a func that returns a new logger.
 	newLogger func() (logging.Logger, error)
 	// sigChan is the channel for signals.
 	sigChan chan os.Signal
+	// configReload is true if the configuration is reloaded on SIGHUP and
+	// when the configuration source changes.
+	configReload bool
+	// hupChan is the channel for SIGHUP signals.
+	hupChan chan os.Signal
 	// cleanups are the cleanups for the service.
 	cleanups []func()
+	// health holds the liveness and readiness checks for the service.
+	health health.Health
+	// healthServer is true if the health endpoints are served.
+	healthServer bool
+	// healthServerOpts are the options for the health server.
+	healthServerOpts []http.ServerOption
+	// shutdownTimeout is the time allowed for the workers to stop, or 0 for
+	// no limit.
+	shutdownTimeout time.Duration
 }
 
 // New returns a new service.
 func New(name string, opts ...Option) (*Service, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Service{
-		name:     name,
-		version:  "v0.0.0",
-		Hooks:    &hookstruct{},
-		ctx:      ctx,
-		cancel:   cancel,
-		config:   config.New(),
-		mockMode: false,
+		name:          name,
+		version:       "v0.0.0",
+		Hooks:         &hookstruct{},
+		ctx:           ctx,
+		cancel:        cancel,
+		flags:         pflag.NewFlagSet(name, pflag.ContinueOnError),
+		envFileSource: newEnvFileSource(),
+		health:        health.New(),
+		mockMode:      false,
 		newLogger: func() (logging.Logger, error) {
 			return logging.New()
 		},
 		sigChan: make(chan os.Signal, 1),
+		hupChan: make(chan os.Signal, 1),
 	}
 	for _, opt := range opts {
 		if err := opt(s); err != nil {
 			return nil, err
 		}
 	}
+	s.config = s.newConfig()
+	if err := s.loadEnvFile(); err != nil {
+		return nil, err
+	}
 	logger, err := s.newLogger()
 	if err != nil {
 		return nil, err
@@ -77,6 +120,24 @@ func New(name string, opts ...Option) (*Service, error) {
 	return s, nil
 }
 
+// newConfig returns the config for the service. Variables are loaded from
+// the command-line flags, then from the environment, then from the
+// configuration source, if any, then from the dotenv file. A warning is
+// logged when a variable is loaded from a deprecated name.
+func (s *Service) newConfig() config.Config {
+	layers := []config.Layer{
+		{Name: "flags", Source: config.NewFlagSource(s.flags)},
+		{Name: "env", Source: config.NewEnvSource()},
+	}
+	if s.configSource != nil {
+		layers = append(layers, config.Layer{Name: "source", Source: s.configSource})
+	}
+	layers = append(layers, config.Layer{Name: "dotenv", Source: s.envFileSource})
+	return config.New(config.WithSource(config.NewChainSource(layers...)), config.WithCollectErrors(), config.WithDeprecationHandler(func(name, deprecated string) {
+		s.logger.Warnw("configuration variable is deprecated", "name", deprecated, "replacement", name)
+	}))
+}
+
 // Name returns the name of the service.
 func (s *Service) Name() string {
 	return s.name
@@ -107,6 +168,13 @@ func (s *Service) Config() config.Config {
 	return s.config
 }
 
+// Health returns the liveness and readiness checks for the service. The
+// service is ready once Run has started all workers and they are ready, and
+// until it begins shutting down.
+func (s *Service) Health() health.Health {
+	return s.health
+}
+
 // MockMode returns true if the service is in mock mode.
 func (s *Service) MockMode() bool {
 	return s.mockMode
@@ -16,10 +16,14 @@ package service
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/neuralnorthwest/mu/config"
 	"github.com/neuralnorthwest/mu/logging"
 	mock_logging "github.com/neuralnorthwest/mu/logging/mock"
 	"github.com/neuralnorthwest/mu/status"
@@ -164,3 +168,36 @@ func Test_Service_New_serviceOptionError(t *testing.T) {
 		t.Error("unexpected service")
 	}
 }
+
+// Test_Service_New_WithConfigSource tests the New function with a config
+// source.
+func Test_Service_New_WithConfigSource(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("MESSAGE: from file\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile returned an error: %v", err)
+	}
+	src, err := config.NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource returned an error: %v", err)
+	}
+	svc, err := New("test-service", WithConfigSource(src))
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := svc.Config().NewString("MESSAGE", "default", "message"); err != nil {
+		t.Fatalf("NewString returned an error: %v", err)
+	}
+	if v := svc.Config().String("MESSAGE"); v != "from file" {
+		t.Errorf("unexpected message: %s, expected: %s", v, "from file")
+	}
+}
+
+// Test_Service_New_WithShutdownTimeoutError tests that New returns an error
+// for a negative shutdown timeout.
+func Test_Service_New_WithShutdownTimeoutError(t *testing.T) {
+	t.Parallel()
+	if _, err := New("test-service", WithShutdownTimeout(-time.Second)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("New() = %v; want %v", err, status.ErrOutOfRange)
+	}
+}
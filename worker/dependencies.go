@@ -0,0 +1,165 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// DependsOn returns an option that makes the worker start only once the
+// named workers are ready. The named workers must be added to the group
+// before it is started, or, if the group has already been started, before
+// the worker is added.
+func DependsOn(names ...string) Option {
+	return func(e *entry) error {
+		e.dependsOn = append(e.dependsOn, names...)
+		return nil
+	}
+}
+
+// WithReadySignal returns an option that makes the worker signal when it is
+// ready, by calling Ready with the context passed to Run. Until then, the
+// workers that depend on it are not started. A worker that returns nil is
+// ready, even if it did not call Ready, so a worker that does some work and
+// returns, such as a database migration, needs no signal. Without this
+// option, a worker is ready as soon as it starts.
+func WithReadySignal() Option {
+	return func(e *entry) error {
+		e.readySignal = true
+		return nil
+	}
+}
+
+// readyKey is the context key for the function that marks a worker ready.
+type readyKey struct{}
+
+// Ready signals that the worker running with ctx is ready, so that the
+// workers that depend on it can start. ctx must be the context passed to
+// Run, or be derived from it. It has no effect if the worker was not added
+// with WithReadySignal, or has already signaled.
+func Ready(ctx context.Context) {
+	if markReady, ok := ctx.Value(readyKey{}).(func()); ok {
+		markReady()
+	}
+}
+
+// markReady marks the worker ready.
+func (e *entry) markReady() {
+	e.readyOnce.Do(func() {
+		close(e.ready)
+	})
+}
+
+// awaitDependencies waits until the dependencies of the worker are ready. It
+// returns false if ctx or stop is canceled first, and an error if a
+// dependency stopped before it was ready.
+func (e *entry) awaitDependencies(ctx, stop context.Context, deps []*entry) (bool, error) {
+	for _, d := range deps {
+		select {
+		case <-d.ready:
+		case <-d.done:
+			select {
+			case <-d.ready:
+			default:
+				return false, fmt.Errorf("worker %s: dependency %s stopped before it was ready", e.name, d.name)
+			}
+		case <-ctx.Done():
+			return false, nil
+		case <-stop.Done():
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// dependencies returns the entries of the dependencies of a worker. The
+// caller must hold g.lock.
+func (g *group) dependencies(e *entry) ([]*entry, error) {
+	deps := make([]*entry, len(e.dependsOn))
+	for i, name := range e.dependsOn {
+		d, ok := g.workers[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: worker %s depends on unknown worker %s", status.ErrNotFound, e.name, name)
+		}
+		deps[i] = d
+	}
+	return deps, nil
+}
+
+// dependents returns the names of the workers that depend on the named
+// worker. The caller must hold g.lock.
+func (g *group) dependents(name string) []string {
+	var names []string
+	for _, n := range g.order {
+		for _, d := range g.workers[n].dependsOn {
+			if d == name {
+				names = append(names, n)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// startOrder returns the workers in the order in which they are started:
+// every worker comes after its dependencies, and workers are otherwise in
+// the order in which they were added. It returns an error if a dependency is
+// unknown or the dependencies form a cycle. The caller must hold g.lock.
+func (g *group) startOrder() ([]*entry, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(g.workers))
+	order := make([]*entry, 0, len(g.workers))
+	var visit func(e *entry, path []string) error
+	visit = func(e *entry, path []string) error {
+		switch state[e.name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, name := range path {
+				if name == e.name {
+					path = path[i:]
+					break
+				}
+			}
+			return fmt.Errorf("%w: dependency cycle %s -> %s", status.ErrInvalidArgument, strings.Join(path, " -> "), e.name)
+		}
+		state[e.name] = visiting
+		deps, err := g.dependencies(e)
+		if err != nil {
+			return err
+		}
+		for _, d := range deps {
+			if err := visit(d, append(path, e.name)); err != nil {
+				return err
+			}
+		}
+		state[e.name] = visited
+		order = append(order, e)
+		return nil
+	}
+	for _, name := range g.order {
+		if err := visit(g.workers[name], nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/neuralnorthwest/mu/retry"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// RestartPolicy determines when a worker is restarted after Run returns.
+type RestartPolicy int
+
+const (
+	// RestartNever never restarts the worker. This is the default.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts the worker when Run returns an error.
+	RestartOnFailure
+	// RestartAlways restarts the worker whenever Run returns, with or
+	// without an error.
+	RestartAlways
+)
+
+// String returns the name of the restart policy.
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	}
+	return fmt.Sprintf("RestartPolicy(%d)", int(p))
+}
+
+// Option is an option for a worker added to a group.
+type Option func(e *entry) error
+
+// WithRestartPolicy returns an option that sets when the worker is
+// restarted. Workers are never restarted after the group context is
+// canceled.
+func WithRestartPolicy(policy RestartPolicy) Option {
+	return func(e *entry) error {
+		if policy < RestartNever || policy > RestartAlways {
+			return fmt.Errorf("%w: %v", status.ErrInvalidArgument, policy)
+		}
+		e.restartPolicy = policy
+		return nil
+	}
+}
+
+// WithBackoff returns an option that sets the function that creates the
+// strategy that determines how long to wait before restarting the worker. If
+// the strategy returns a negative duration, the worker is not restarted, and
+// its error is returned to the group. The default is retry.Exponential().
+//
+// Strategies keep state, so newStrategy must return a new strategy each time
+// it is called. The worker gets a new strategy, and so starts over with the
+// shortest delay and all its attempts, once it has run for at least the
+// restart window set by WithMaxRestarts, or for a minute if there is none,
+// and whenever it returns nil.
+func WithBackoff(newStrategy func() retry.Strategy) Option {
+	return func(e *entry) error {
+		if newStrategy == nil {
+			return fmt.Errorf("%w: nil backoff", status.ErrInvalidArgument)
+		}
+		e.newBackoff = newStrategy
+		return nil
+	}
+}
+
+// WithMaxRestarts returns an option that limits how often the worker is
+// restarted. If the worker would be restarted more than max times within
+// window, it is not restarted, and a *RestartLimitError is returned to the
+// group instead, which stops the group. A window of 0 counts every restart.
+// By default, there is no limit.
+func WithMaxRestarts(max int, window time.Duration) Option {
+	return func(e *entry) error {
+		if max < 0 || window < 0 {
+			return status.ErrOutOfRange
+		}
+		e.maxRestarts = max
+		e.restartWindow = window
+		return nil
+	}
+}
+
+// WithStopTimeout returns an option that limits the time the worker has to
+// stop when the group stops. If the worker has not returned when it expires,
+// it is logged and reported in the error returned by Wait, and the group
+// moves on to stop the next worker. By default, the worker is only limited by
+// WithShutdownTimeout.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(e *entry) error {
+		if timeout < 0 {
+			return status.ErrOutOfRange
+		}
+		e.stopTimeout = timeout
+		return nil
+	}
+}
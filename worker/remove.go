@@ -0,0 +1,184 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// Remove stops a worker and removes it from the worker group, without
+// stopping the other workers. It cancels the context of the worker and waits
+// for it to stop, for at most its stop timeout, and returns the error it
+// returned, if any. A worker that other workers depend on cannot be removed
+// until they are. If the group has not been started, the worker is only
+// removed; if it is stopping, Remove only waits for the worker to stop.
+// Removing the last worker stops the group if Wait has been called, as when
+// the last worker returns; before that, the group keeps running, and workers
+// may be added to it again.
+func (g *group) Remove(name string) error {
+	g.lock.Lock()
+	e, ok := g.workers[name]
+	if !ok || e.removed {
+		g.lock.Unlock()
+		return fmt.Errorf("%w: %s", status.ErrNotFound, name)
+	}
+	if dependents := g.dependents(name); len(dependents) > 0 {
+		g.lock.Unlock()
+		return fmt.Errorf("%w: workers %s depend on worker %s", status.ErrInvalidArgument, strings.Join(dependents, ", "), name)
+	}
+	if g.started && g.ctx.Err() != nil {
+		g.lock.Unlock()
+		return e.wait()
+	}
+	g.forget(e)
+	if !g.started {
+		g.lock.Unlock()
+		return nil
+	}
+	if g.stop(e) {
+		g.release()
+	}
+	g.lock.Unlock()
+	return e.wait()
+}
+
+// Replace replaces a worker with another, which keeps the options of the
+// worker it replaces, including its dependencies. The new worker gets a new
+// backoff strategy and restart budget. If the group has been
+// started, Replace cancels the context of the worker, waits for it to stop,
+// for at most its stop timeout, and starts the new worker in its place,
+// without stopping the other workers. If the worker does not stop in time,
+// it is removed, and the new worker is not started. Replace returns an error
+// if the group is stopping.
+func (g *group) Replace(name string, worker Worker) error {
+	g.lock.Lock()
+	e, ok := g.workers[name]
+	if !ok || e.removed {
+		g.lock.Unlock()
+		return fmt.Errorf("%w: %s", status.ErrNotFound, name)
+	}
+	// The options were applied to e without an error.
+	n, _ := newEntry(name, worker, e.opts)
+	if !g.started {
+		g.workers[name] = n
+		g.lock.Unlock()
+		return nil
+	}
+	if g.ctx.Err() != nil {
+		g.lock.Unlock()
+		return errStopping
+	}
+	deps, err := g.dependencies(n)
+	if err != nil {
+		g.lock.Unlock()
+		return err
+	}
+	running := g.stop(e)
+	g.lock.Unlock()
+	err = e.wait()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	// A running worker stays counted as active until its replacement
+	// starts, so that the group does not stop in between.
+	if !e.stopped() || g.ctx.Err() != nil {
+		g.forget(e)
+		if running {
+			g.release()
+		}
+		if e.stopped() {
+			err = errStopping
+		}
+		return err
+	}
+	if err != nil {
+		g.logger.Warnw("replaced worker failed", "worker", name, "err", err)
+	}
+	g.workers[name] = n
+	for i, r := range g.running {
+		if r == e {
+			g.running[i] = n
+		}
+	}
+	if !running {
+		g.active++
+	}
+	g.launch(n, deps)
+	return nil
+}
+
+// stop marks a worker removed and cancels it, if it has not stopped. It
+// returns true if the worker was running. The caller must hold g.lock.
+func (g *group) stop(e *entry) bool {
+	e.removed = true
+	if e.stopped() {
+		return false
+	}
+	e.stopping = true
+	e.cancel()
+	return true
+}
+
+// forget removes a worker from the group. The caller must hold g.lock.
+func (g *group) forget(e *entry) {
+	delete(g.workers, e.name)
+	for i, name := range g.order {
+		if name == e.name {
+			g.order = append(g.order[:i], g.order[i+1:]...)
+			break
+		}
+	}
+	for i, r := range g.running {
+		if r == e {
+			g.running = append(g.running[:i], g.running[i+1:]...)
+			break
+		}
+	}
+}
+
+// stopped returns true if the worker has stopped.
+func (e *entry) stopped() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait waits for a canceled worker to stop, for at most its stop timeout,
+// and returns the error it returned, if any, other than the cancellation.
+func (e *entry) wait() error {
+	var timeout <-chan time.Time
+	if e.stopTimeout > 0 {
+		timer := time.NewTimer(e.stopTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-e.done:
+	case <-timeout:
+		return fmt.Errorf("%w: worker %s did not stop within %v", context.DeadlineExceeded, e.name, e.stopTimeout)
+	}
+	if errors.Is(e.err, context.Canceled) {
+		return nil
+	}
+	return e.err
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors is a list of errors returned by the workers of a group and by
+// stopping them.
+type Errors []error
+
+// Error implements error.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any error in the list matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the list that matches target.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// detachedContext is a context that has the values of its parent, but is
+// never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline implements context.Context.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// shutdown waits for the group to stop, then stops the workers one at a
+// time, in the reverse of the order in which they were started, so that
+// every worker stops before the workers it depends on. It closes g.stopped
+// when done.
+func (g *group) shutdown() {
+	defer close(g.stopped)
+	<-g.ctx.Done()
+	var deadline <-chan time.Time
+	if g.shutdownTimeout > 0 {
+		timer := time.NewTimer(g.shutdownTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		e := g.nextToStop()
+		if e == nil {
+			return
+		}
+		if !g.stopWorker(e, deadline) {
+			g.abandon()
+			return
+		}
+	}
+}
+
+// nextToStop cancels the most recently started worker that has not been
+// canceled, and returns it. It returns nil if every worker was canceled.
+func (g *group) nextToStop() *entry {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for i := len(g.running) - 1; i >= 0; i-- {
+		if e := g.running[i]; !e.stopping {
+			e.stopping = true
+			e.cancel()
+			return e
+		}
+	}
+	return nil
+}
+
+// stopWorker waits for a canceled worker to stop, until its stop timeout
+// expires. It returns false if the shutdown deadline expires first.
+func (g *group) stopWorker(e *entry, deadline <-chan time.Time) bool {
+	var timeout <-chan time.Time
+	if e.stopTimeout > 0 {
+		timer := time.NewTimer(e.stopTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-e.done:
+	case <-timeout:
+		g.logger.Errorw("worker did not stop in time", "worker", e.name, "timeout", e.stopTimeout)
+		g.addError(fmt.Errorf("%w: worker %s did not stop within %v", context.DeadlineExceeded, e.name, e.stopTimeout))
+	case <-deadline:
+		return false
+	}
+	return true
+}
+
+// abandon cancels every worker that has not been canceled, once the
+// shutdown deadline has expired, and reports the workers that have not
+// stopped.
+func (g *group) abandon() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	var stragglers []string
+	for i := len(g.running) - 1; i >= 0; i-- {
+		e := g.running[i]
+		if !e.stopping {
+			e.stopping = true
+			e.cancel()
+		}
+		select {
+		case <-e.done:
+		default:
+			stragglers = append(stragglers, e.name)
+		}
+	}
+	if len(stragglers) == 0 {
+		return
+	}
+	g.logger.Errorw("workers did not stop before the shutdown timeout", "workers", stragglers, "timeout", g.shutdownTimeout)
+	g.errs = append(g.errs, fmt.Errorf("%w: workers %s did not stop within %v", context.DeadlineExceeded, strings.Join(stragglers, ", "), g.shutdownTimeout))
+}
+
+// addError adds an error to the errors returned by Wait.
+func (g *group) addError(err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.errs = append(g.errs, err)
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/neuralnorthwest/mu/logging"
+	"github.com/neuralnorthwest/mu/retry"
+)
+
+// backoffReset is how long a worker must run before its backoff starts over,
+// if it has no restart window.
+const backoffReset = time.Minute
+
+// entry is a worker in a group, with its options.
+type entry struct {
+	// name is the name of the worker.
+	name string
+	// worker is the worker.
+	worker Worker
+	// opts are the options of the worker, which are applied again to the
+	// worker that replaces it.
+	opts []Option
+	// restartPolicy determines when the worker is restarted.
+	restartPolicy RestartPolicy
+	// newBackoff creates the strategy that determines how long to wait
+	// before restarting the worker.
+	newBackoff func() retry.Strategy
+	// backoff is the current strategy created by newBackoff.
+	backoff retry.Strategy
+	// maxRestarts is the number of restarts allowed within restartWindow,
+	// or -1 for no limit.
+	maxRestarts int
+	// restartWindow is the window in which restarts are counted, or 0 to
+	// count every restart.
+	restartWindow time.Duration
+	// dependsOn are the names of the workers that must be ready before the
+	// worker starts.
+	dependsOn []string
+	// readySignal is true if the worker signals when it is ready by calling
+	// Ready.
+	readySignal bool
+	// ready is closed when the worker is ready.
+	ready chan struct{}
+	// readyOnce closes ready once.
+	readyOnce sync.Once
+	// done is closed when the worker has stopped. It is closed with the
+	// lock of the group held.
+	done chan struct{}
+	// err is the error returned by the worker. It is set before done is
+	// closed.
+	err error
+	// stopTimeout is the time allowed for the worker to stop, or 0 for no
+	// limit other than that of the group.
+	stopTimeout time.Duration
+	// cancel cancels the context of the worker.
+	cancel context.CancelFunc
+	// stopping is true once the group has canceled the worker. It is
+	// guarded by the lock of the group.
+	stopping bool
+	// removed is true once the worker has been removed from the group by
+	// Remove or Replace. It is guarded by the lock of the group.
+	removed bool
+}
+
+// newEntry returns an entry for a worker, with the given options applied.
+func newEntry(name string, worker Worker, opts []Option) (*entry, error) {
+	e := &entry{
+		name:        name,
+		worker:      worker,
+		opts:        opts,
+		maxRestarts: -1,
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	if e.newBackoff == nil {
+		e.newBackoff = func() retry.Strategy {
+			return retry.Exponential()
+		}
+	}
+	e.backoff = e.newBackoff()
+	return e, nil
+}
+
+// RestartLimitError is returned to a group when a worker would be restarted
+// more often than WithMaxRestarts allows.
+type RestartLimitError struct {
+	// Worker is the name of the worker.
+	Worker string
+	// Restarts is the number of restarts allowed.
+	Restarts int
+	// Window is the window in which restarts are counted, or 0 if every
+	// restart is counted.
+	Window time.Duration
+	// Err is the error returned by the last run of the worker, or nil if it
+	// returned without an error.
+	Err error
+}
+
+// Error implements error.
+func (e *RestartLimitError) Error() string {
+	msg := fmt.Sprintf("worker %s exceeded %d restarts", e.Worker, e.Restarts)
+	if e.Window > 0 {
+		msg += fmt.Sprintf(" within %v", e.Window)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the error returned by the last run of the worker.
+func (e *RestartLimitError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is returned to a group when a worker panics.
+type PanicError struct {
+	// Worker is the name of the worker.
+	Worker string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack string
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("worker %s panicked: %v", e.Worker, e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// start waits for the dependencies of the worker to be ready, then runs it
+// under supervision.
+func (e *entry) start(ctx, stop context.Context, deps []*entry, logger logging.Logger) error {
+	if ok, err := e.awaitDependencies(ctx, stop, deps); !ok {
+		return err
+	}
+	if !e.readySignal {
+		e.markReady()
+	}
+	err := e.supervise(ctx, stop, logger)
+	if err == nil {
+		e.markReady()
+	}
+	return err
+}
+
+// supervise runs the worker until it returns and its restart policy does not
+// restart it, the restart limit is exceeded, or ctx or stop is canceled.
+func (e *entry) supervise(ctx, stop context.Context, logger logging.Logger) error {
+	var restarts []time.Time
+	for {
+		started := time.Now()
+		err := e.run(ctx, logger)
+		if ctx.Err() != nil || stop.Err() != nil || !e.restart(err) {
+			return err
+		}
+		now := time.Now()
+		if err == nil || now.Sub(started) >= e.resetAfter() {
+			e.backoff = e.newBackoff()
+		}
+		restarts = e.recent(restarts, now)
+		if e.maxRestarts >= 0 && len(restarts) >= e.maxRestarts {
+			return &RestartLimitError{Worker: e.name, Restarts: e.maxRestarts, Window: e.restartWindow, Err: err}
+		}
+		delay := e.backoff.Next(err)
+		if delay < 0 {
+			return err
+		}
+		restarts = append(restarts, now)
+		logger.Warnw("restarting worker", "err", err, "restarts", len(restarts), "delay", delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-stop.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// run runs the worker once. A panic in the worker is logged and returned as
+// a *PanicError, so that it is handled like any other error.
+func (e *entry) run(ctx context.Context, logger logging.Logger) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := &PanicError{Worker: e.name, Value: r, Stack: string(debug.Stack())}
+			logger.Errorw("worker panicked", "panic", r, "stack", perr.Stack)
+			err = perr
+		}
+	}()
+	return e.worker.Run(ctx, logger)
+}
+
+// restart returns true if the restart policy restarts the worker after Run
+// returned err.
+func (e *entry) restart(err error) bool {
+	switch e.restartPolicy {
+	case RestartOnFailure:
+		return err != nil
+	case RestartAlways:
+		return true
+	}
+	return false
+}
+
+// resetAfter returns how long the worker must run before its backoff starts
+// over: the restart window, or backoffReset if there is none.
+func (e *entry) resetAfter() time.Duration {
+	if e.restartWindow > 0 {
+		return e.restartWindow
+	}
+	return backoffReset
+}
+
+// recent returns the restarts that are within the restart window at now.
+func (e *entry) recent(restarts []time.Time, now time.Time) []time.Time {
+	if e.restartWindow == 0 {
+		return restarts
+	}
+	i := 0
+	for i < len(restarts) && now.Sub(restarts[i]) >= e.restartWindow {
+		i++
+	}
+	return restarts[i:]
+}
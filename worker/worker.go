@@ -16,12 +16,13 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/neuralnorthwest/mu/logging"
 	"github.com/neuralnorthwest/mu/status"
-	"golang.org/x/sync/errgroup"
 )
 
 // Worker is an interface for a worker.
@@ -35,55 +36,130 @@ type Worker interface {
 type Group interface {
 	// Add adds a worker to the worker group. The worker will be started when the
 	// worker group is started. If the group has already been started, the worker
-	// will be started immediately.
-	Add(name string, worker Worker) error
+	// will be started immediately, unless the group is stopping. The options
+	// control how the worker is supervised.
+	Add(name string, worker Worker, opts ...Option) error
 	// Run runs the worker group. This will start all the workers in the
 	// worker group. This will block until the context is canceled or a worker
 	// returns an error.
 	Run(ctx context.Context, logger logging.Logger) error
 	// Start starts the worker group. This will start all the workers in the
-	// worker group. This will not block. To wait for the workers to stop, call
-	// Wait and cancel the context.
+	// worker group, each once its dependencies are ready, and block until
+	// every worker is ready or the group stops. It returns an error if the
+	// dependencies are unknown or form a cycle. To wait for the workers to
+	// stop, call Wait and cancel the context.
 	Start(ctx context.Context, logger logging.Logger) error
-	// Wait waits for the worker group to stop.
+	// Wait waits for the worker group to stop: until the context is canceled,
+	// a worker returns an error, or every worker has returned or been
+	// removed. Workers are stopped in the reverse of the order in which they
+	// were started. If several errors occur, they are returned as Errors.
 	Wait() error
+	// Remove stops a worker and removes it from the worker group, without
+	// stopping the other workers. It waits for the worker to stop, and
+	// returns the error it returned, if any. Removing the last worker while
+	// Wait is waiting stops the group.
+	Remove(name string) error
+	// Replace replaces a worker with another, which keeps the options of the
+	// worker it replaces. If the group has been started, the worker is
+	// stopped and the new worker is started in its place, without stopping
+	// the other workers.
+	Replace(name string, worker Worker) error
 }
 
 // group is a group of workers.
 type group struct {
 	// lock is the lock for the worker group.
 	lock sync.Mutex
-	// ctx is the context.
+	// ctx is the context of the group. It is canceled when the group stops.
 	ctx context.Context
+	// cancel cancels ctx.
+	cancel context.CancelFunc
+	// base is the parent of the contexts of the workers. It has the values
+	// of the context passed to Start, but is not canceled with it, so that
+	// the workers can be stopped one at a time.
+	base context.Context
 	// workers is a map of workers.
-	workers map[string]Worker
-	// eg is the errgroup.
-	eg *errgroup.Group
+	workers map[string]*entry
+	// order holds the names of the workers in the order they were added.
+	order []string
+	// running holds the started workers, in the order they were started.
+	running []*entry
+	// active is the number of workers that have not stopped, not counting
+	// removed workers.
+	active int
 	// started is true if the worker group has been started.
 	started bool
+	// waiting is true once Wait has been called. From then on, the group
+	// stops when every worker has stopped.
+	waiting bool
+	// stopped is closed when the group has stopped.
+	stopped chan struct{}
+	// errs holds the errors returned by workers and the shutdown errors.
+	errs Errors
+	// shutdownTimeout is the time allowed for all workers to stop, or 0 for
+	// no limit.
+	shutdownTimeout time.Duration
 	// logger is the logger for the worker group.
 	logger logging.Logger
 }
 
+// errStopping is returned when a worker is added to, or replaced in, a
+// group that is stopping.
+var errStopping = fmt.Errorf("%w: worker group is stopping", context.Canceled)
+
+// GroupOption is an option for a worker group.
+type GroupOption func(g *group)
+
+// WithShutdownTimeout returns a GroupOption that limits the time allowed for
+// all workers to stop. When it expires, the workers that have not stopped
+// are canceled at once, logged, and reported in the error returned by Wait.
+// By default, there is no limit.
+func WithShutdownTimeout(timeout time.Duration) GroupOption {
+	return func(g *group) {
+		g.shutdownTimeout = timeout
+	}
+}
+
 // NewGroup creates a new worker group.
-func NewGroup() Group {
-	return &group{
-		workers: make(map[string]Worker),
+func NewGroup(opts ...GroupOption) Group {
+	g := &group{
+		workers: make(map[string]*entry),
+		stopped: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 // Add adds a worker to the worker group. The worker will be started when the
 // worker group is started. If the group has already been started, the worker
-// will be started immediately.
-func (g *group) Add(name string, worker Worker) error {
+// will be started immediately, unless the group is stopping, in which case
+// Add returns an error that wraps context.Canceled. The options control how
+// the worker is supervised.
+func (g *group) Add(name string, worker Worker, opts ...Option) error {
+	e, err := newEntry(name, worker, opts)
+	if err != nil {
+		return err
+	}
 	g.lock.Lock()
 	defer g.lock.Unlock()
 	if _, ok := g.workers[name]; ok {
 		return fmt.Errorf("%w: %s", status.ErrAlreadyExists, name)
 	}
-	g.workers[name] = worker
+	if g.started && g.ctx.Err() != nil {
+		return errStopping
+	}
+	var deps []*entry
 	if g.started {
-		return g.startWorker(name, worker)
+		if deps, err = g.dependencies(e); err != nil {
+			return err
+		}
+	}
+	g.workers[name] = e
+	g.order = append(g.order, name)
+	if g.started {
+		g.startWorker(e, deps)
 	}
 	return nil
 }
@@ -98,41 +174,128 @@ func (g *group) Run(ctx context.Context, logger logging.Logger) error {
 }
 
 // Start starts the worker group. This will start all the workers in the
-// worker group. This will not block. To wait for the workers to stop, call
+// worker group, each once its dependencies are ready, and block until every
+// worker is ready or the group stops. To wait for the workers to stop, call
 // Wait after canceling the context.
 func (g *group) Start(ctx context.Context, logger logging.Logger) error {
 	g.lock.Lock()
-	defer g.lock.Unlock()
 	if g.started {
+		g.lock.Unlock()
 		return status.ErrAlreadyStarted
 	}
-	g.ctx = ctx
-	g.eg, g.ctx = errgroup.WithContext(ctx)
+	order, err := g.startOrder()
+	if err != nil {
+		g.lock.Unlock()
+		return err
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	g.base = detachedContext{ctx}
 	g.logger = logger
 	g.started = true
-	for name, worker := range g.workers {
-		if err := g.startWorker(name, worker); err != nil {
-			return err
+	for _, e := range order {
+		// The dependencies were resolved by startOrder.
+		deps, _ := g.dependencies(e)
+		g.startWorker(e, deps)
+	}
+	groupCtx := g.ctx
+	g.lock.Unlock()
+	go g.shutdown()
+	for _, e := range order {
+		select {
+		case <-e.ready:
+		case <-e.done:
+		case <-groupCtx.Done():
+			return nil
 		}
 	}
 	return nil
 }
 
-// Wait waits for the worker group to stop.
+// Wait waits for the worker group to stop. The group stops when the context
+// passed to Start is canceled, when a worker returns an error, or, once Wait
+// has been called, when every worker has returned or been removed, which is
+// at once if there are none. Workers added after Start
+// but before Wait are waited for too. Wait returns the errors returned by the
+// workers and the errors that occurred while stopping them. If there are
+// several, they are returned as Errors.
 func (g *group) Wait() error {
 	g.lock.Lock()
 	if !g.started {
 		g.lock.Unlock()
 		return status.ErrNotStarted
 	}
+	g.waiting = true
+	if g.active == 0 {
+		g.cancel()
+	}
 	g.lock.Unlock()
-	return g.eg.Wait()
+	<-g.stopped
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	switch len(g.errs) {
+	case 0:
+		return nil
+	case 1:
+		return g.errs[0]
+	}
+	return append(Errors{}, g.errs...)
 }
 
-// startWorker starts a worker.
-func (g *group) startWorker(name string, worker Worker) error {
-	g.eg.Go(func() error {
-		return worker.Run(g.ctx, g.logger.With("worker", name))
-	})
-	return nil
+// startWorker starts a worker under supervision, once its dependencies are
+// ready. The caller must hold g.lock.
+func (g *group) startWorker(e *entry, deps []*entry) {
+	g.running = append(g.running, e)
+	g.active++
+	g.launch(e, deps)
+}
+
+// launch runs a worker in its own goroutine, with its own context. The
+// caller must hold g.lock.
+func (g *group) launch(e *entry, deps []*entry) {
+	ctx, cancel := context.WithCancel(g.base)
+	ctx = context.WithValue(ctx, readyKey{}, e.markReady)
+	e.cancel = cancel
+	stop, logger := g.ctx, g.logger.With("worker", e.name)
+	go func() {
+		g.exited(e, e.start(ctx, stop, deps, logger))
+	}()
+}
+
+// exited records that a worker has stopped. The first error returned by a
+// worker stops the group. Once the group is stopping, the errors returned by
+// the workers it cancels are kept too, except for the cancellation itself;
+// other errors are only logged. Once Wait has been called, the group also
+// stops when every worker has stopped. The error of a removed worker is left
+// to Remove or Replace.
+func (g *group) exited(e *entry, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	e.err = err
+	close(e.done)
+	if e.removed {
+		return
+	}
+	switch {
+	case err == nil:
+	case e.stopping:
+		if !errors.Is(err, context.Canceled) {
+			g.errs = append(g.errs, err)
+		}
+	case g.ctx.Err() == nil:
+		g.errs = append(g.errs, err)
+		g.cancel()
+	default:
+		g.logger.Errorw("worker failed while the group was stopping", "worker", e.name, "err", err)
+	}
+	g.release()
+}
+
+// release records that a worker has stopped or been removed. Once Wait has
+// been called, the group stops when no worker is left running. The caller
+// must hold g.lock.
+func (g *group) release() {
+	g.active--
+	if g.active == 0 && g.waiting {
+		g.cancel()
+	}
 }
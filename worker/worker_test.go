@@ -0,0 +1,861 @@
+// Copyright 2023 Scott M. Long
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/neuralnorthwest/mu/logging"
+	mock_logging "github.com/neuralnorthwest/mu/logging/mock"
+	"github.com/neuralnorthwest/mu/retry"
+	"github.com/neuralnorthwest/mu/status"
+)
+
+// errTransient is returned by failing test workers.
+var errTransient = errors.New("transient failure")
+
+// testFuncWorker is a worker that runs a function.
+type testFuncWorker func(ctx context.Context) error
+
+// Run implements Worker.
+func (f testFuncWorker) Run(ctx context.Context, logger logging.Logger) error {
+	return f(ctx)
+}
+
+// newTestLogger returns a mock logger that accepts any message.
+func newTestLogger(t *testing.T) logging.Logger {
+	t.Helper()
+	logger := mock_logging.NewMockLogger(gomock.NewController(t))
+	logger.EXPECT().With(gomock.Any()).Return(logger).AnyTimes()
+	logger.EXPECT().Debugw(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Infow(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Warnw(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Errorw(gomock.Any(), gomock.Any()).AnyTimes()
+	return logger
+}
+
+// fastBackoff returns a backoff strategy for tests.
+func fastBackoff() retry.Strategy {
+	return retry.Fixed(retry.WithBaseInterval(time.Millisecond))
+}
+
+// fixedBackoff returns a function that creates a fixed backoff strategy with
+// the given interval and options.
+func fixedBackoff(interval time.Duration, opts ...retry.StrategyOption) func() retry.Strategy {
+	return func() retry.Strategy {
+		return retry.Fixed(append([]retry.StrategyOption{retry.WithBaseInterval(interval)}, opts...)...)
+	}
+}
+
+// Test_Group_Run tests that Run runs every worker and returns the first
+// error.
+func Test_Group_Run(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	var ran int32
+	for _, name := range []string{"a", "b"} {
+		if err := g.Add(name, testFuncWorker(func(ctx context.Context) error {
+			atomic.AddInt32(&ran, 1)
+			<-ctx.Done()
+			return nil
+		})); err != nil {
+			t.Fatalf("Add() = %v; want nil", err)
+		}
+	}
+	if err := g.Add("failing", testFuncWorker(func(ctx context.Context) error {
+		return errTransient
+	})); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("a", testFuncWorker(nil)); !errors.Is(err, status.ErrAlreadyExists) {
+		t.Errorf("Add() = %v; want %v", err, status.ErrAlreadyExists)
+	}
+	if err := g.Run(context.Background(), newTestLogger(t)); !errors.Is(err, errTransient) {
+		t.Errorf("Run() = %v; want %v", err, errTransient)
+	}
+	if ran != 2 {
+		t.Errorf("workers ran = %v; want 2", ran)
+	}
+}
+
+// Test_Group_Restart_Case is a test case for Test_Group_Restart.
+type Test_Group_Restart_Case struct {
+	// name is the name of the test case.
+	name string
+	// failures is the number of times the worker fails before it returns
+	// nil.
+	failures int32
+	// opts are the options for the worker.
+	opts []Option
+	// runs is the expected number of runs.
+	runs int32
+	// err is the expected error from Wait.
+	err error
+}
+
+// Test_Group_Restart tests restart policies.
+func Test_Group_Restart(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []Test_Group_Restart_Case{
+		{
+			name:     "never",
+			failures: 1,
+			runs:     1,
+			err:      errTransient,
+		},
+		{
+			name:     "on-failure",
+			failures: 3,
+			opts:     []Option{WithRestartPolicy(RestartOnFailure), WithBackoff(fastBackoff)},
+			runs:     4,
+		},
+		{
+			name:     "always",
+			failures: 1,
+			opts:     []Option{WithRestartPolicy(RestartAlways), WithBackoff(fastBackoff), WithMaxRestarts(2, 0)},
+			runs:     3,
+			err:      &RestartLimitError{},
+		},
+		{
+			name:     "budget",
+			failures: 10,
+			opts:     []Option{WithRestartPolicy(RestartOnFailure), WithBackoff(fastBackoff), WithMaxRestarts(2, time.Minute)},
+			runs:     3,
+			err:      errTransient,
+		},
+		{
+			name:     "window",
+			failures: 5,
+			opts:     []Option{WithRestartPolicy(RestartOnFailure), WithBackoff(fixedBackoff(20 * time.Millisecond)), WithMaxRestarts(1, 10*time.Millisecond)},
+			runs:     6,
+		},
+		{
+			name:     "backoff-stops",
+			failures: 10,
+			opts:     []Option{WithRestartPolicy(RestartOnFailure), WithBackoff(fixedBackoff(time.Millisecond, retry.WithMaxAttempts(2)))},
+			runs:     3,
+			err:      errTransient,
+		},
+		{
+			name:     "backoff-resets",
+			failures: 0,
+			opts:     []Option{WithRestartPolicy(RestartAlways), WithBackoff(fixedBackoff(time.Millisecond, retry.WithMaxAttempts(1))), WithMaxRestarts(3, 0)},
+			runs:     4,
+			err:      &RestartLimitError{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var runs int32
+			g := NewGroup()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := g.Add("worker", testFuncWorker(func(ctx context.Context) error {
+				if atomic.AddInt32(&runs, 1) <= tc.failures {
+					return errTransient
+				}
+				return nil
+			}), tc.opts...); err != nil {
+				t.Fatalf("Add() = %v; want nil", err)
+			}
+			err := g.Run(ctx, newTestLogger(t))
+			var limitErr *RestartLimitError
+			switch {
+			case tc.err == nil && err != nil:
+				t.Errorf("Run() = %v; want nil", err)
+			case errors.As(tc.err, &limitErr) && !errors.As(err, &limitErr):
+				t.Errorf("Run() = %v; want *RestartLimitError", err)
+			case tc.err != nil && !errors.Is(err, tc.err) && !errors.As(tc.err, &limitErr):
+				t.Errorf("Run() = %v; want %v", err, tc.err)
+			}
+			if runs != tc.runs {
+				t.Errorf("runs = %v; want %v", runs, tc.runs)
+			}
+		})
+	}
+}
+
+// Test_Group_Restart_BackoffReset tests that the backoff of a worker starts
+// over after it runs for at least the restart window.
+func Test_Group_Restart_BackoffReset(t *testing.T) {
+	t.Parallel()
+	var runs int32
+	g := NewGroup()
+	if err := g.Add("worker", testFuncWorker(func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) > 3 {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+		return errTransient
+	}), WithRestartPolicy(RestartOnFailure), WithBackoff(fixedBackoff(time.Millisecond, retry.WithMaxAttempts(1))), WithMaxRestarts(10, 10*time.Millisecond)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Run(context.Background(), newTestLogger(t)); err != nil {
+		t.Errorf("Run() = %v; want nil", err)
+	}
+	if runs != 4 {
+		t.Errorf("runs = %v; want 4", runs)
+	}
+}
+
+// Test_Group_Restart_Canceled tests that a worker is not restarted after the
+// group context is canceled.
+func Test_Group_Restart_Canceled(t *testing.T) {
+	t.Parallel()
+	var runs int32
+	g := NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := g.Add("worker", testFuncWorker(func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		<-ctx.Done()
+		return nil
+	}), WithRestartPolicy(RestartAlways)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+	if runs != 1 {
+		t.Errorf("runs = %v; want 1", runs)
+	}
+}
+
+// Test_Add_Options tests that invalid options are rejected.
+func Test_Add_Options(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	if err := g.Add("a", testFuncWorker(nil), WithRestartPolicy(RestartPolicy(7))); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Add() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	if err := g.Add("a", testFuncWorker(nil), WithMaxRestarts(-1, 0)); !errors.Is(err, status.ErrOutOfRange) {
+		t.Errorf("Add() = %v; want %v", err, status.ErrOutOfRange)
+	}
+}
+
+// Test_Group_Panic tests that a panic in a worker is returned as a
+// *PanicError.
+func Test_Group_Panic(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	if err := g.Add("panicky", testFuncWorker(func(ctx context.Context) error {
+		panic(errTransient)
+	})); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	err := g.Run(context.Background(), newTestLogger(t))
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Run() = %v; want *PanicError", err)
+	}
+	if perr.Worker != "panicky" {
+		t.Errorf("Worker = %v; want panicky", perr.Worker)
+	}
+	if !strings.Contains(perr.Stack, "Test_Group_Panic") {
+		t.Errorf("Stack = %v; want stack of the panic", perr.Stack)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Errorf("Run() = %v; want %v", err, errTransient)
+	}
+	if msg := err.Error(); msg != "worker panicky panicked: transient failure" {
+		t.Errorf("Error() = %v; want worker panicky panicked: transient failure", msg)
+	}
+}
+
+// Test_Group_Panic_Restart tests that a worker that panics is restarted
+// like a worker that fails.
+func Test_Group_Panic_Restart(t *testing.T) {
+	t.Parallel()
+	var runs int32
+	g := NewGroup()
+	if err := g.Add("panicky", testFuncWorker(func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			panic("boom")
+		}
+		return nil
+	}), WithRestartPolicy(RestartOnFailure), WithBackoff(fastBackoff)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Run(context.Background(), newTestLogger(t)); err != nil {
+		t.Errorf("Run() = %v; want nil", err)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %v; want 2", runs)
+	}
+}
+
+// Test_Group_DependsOn tests that workers start in dependency order, and
+// that Start waits until every worker is ready. The test releases the
+// dependencies one at a time, so the order does not depend on timing.
+func Test_Group_DependsOn(t *testing.T) {
+	t.Parallel()
+	var lock sync.Mutex
+	var events []string
+	record := func(event string) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, event)
+	}
+	releaseCache, releaseMigrator := make(chan struct{}), make(chan struct{})
+	migratorDone, httpStarted := make(chan struct{}), make(chan struct{})
+	g := NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Add("http", testFuncWorker(func(ctx context.Context) error {
+		record("http started")
+		close(httpStarted)
+		<-ctx.Done()
+		return nil
+	}), DependsOn("migrator", "cache")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("cache", testFuncWorker(func(ctx context.Context) error {
+		<-releaseCache
+		record("cache ready")
+		Ready(ctx)
+		<-ctx.Done()
+		return nil
+	}), WithReadySignal()); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("migrator", testFuncWorker(func(ctx context.Context) error {
+		<-releaseMigrator
+		record("migrator done")
+		close(migratorDone)
+		return nil
+	}), WithReadySignal()); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	started := make(chan error, 1)
+	go func() {
+		started <- g.Start(ctx, newTestLogger(t))
+	}()
+	close(releaseMigrator)
+	<-migratorDone
+	close(releaseCache)
+	if err := <-started; err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	lock.Lock()
+	got := append([]string{}, events...)
+	lock.Unlock()
+	// Start returns once the dependencies are ready; the dependent worker
+	// may not have started yet.
+	if len(got) < 2 {
+		t.Fatalf("events = %v; want the dependencies ready before Start returns", got)
+	}
+	if diff := cmp.Diff([]string{"migrator done", "cache ready"}, got[:2]); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	<-httpStarted
+	lock.Lock()
+	got = append([]string{}, events...)
+	lock.Unlock()
+	if diff := cmp.Diff([]string{"migrator done", "cache ready", "http started"}, got); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+}
+
+// Test_Group_DependsOn_Errors tests that unknown dependencies and dependency
+// cycles are detected.
+func Test_Group_DependsOn_Errors(t *testing.T) {
+	t.Parallel()
+	logger := newTestLogger(t)
+	g := NewGroup()
+	for _, w := range [][]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"d"}} {
+		if err := g.Add(w[0], testFuncWorker(nil), DependsOn(w[1:]...)); err != nil {
+			t.Fatalf("Add() = %v; want nil", err)
+		}
+	}
+	err := g.Start(context.Background(), logger)
+	if !errors.Is(err, status.ErrInvalidArgument) || !strings.Contains(err.Error(), "dependency cycle a -> b -> c -> a") {
+		t.Errorf("Start() = %v; want dependency cycle a -> b -> c -> a", err)
+	}
+	g = NewGroup()
+	if err := g.Add("a", testFuncWorker(nil), DependsOn("missing")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Start(context.Background(), logger); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Start() = %v; want %v", err, status.ErrNotFound)
+	}
+	g = NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, logger); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	if err := g.Add("a", testFuncWorker(nil), DependsOn("missing")); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Add() = %v; want %v", err, status.ErrNotFound)
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+}
+
+// Test_Group_DependsOn_Failure tests that the dependents of a worker that
+// fails before it is ready are not started.
+func Test_Group_DependsOn_Failure(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	started := false
+	if err := g.Add("db", testFuncWorker(func(ctx context.Context) error {
+		return errTransient
+	}), WithReadySignal()); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("http", testFuncWorker(func(ctx context.Context) error {
+		started = true
+		return nil
+	}), DependsOn("db")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Run(context.Background(), newTestLogger(t)); !errors.Is(err, errTransient) {
+		t.Errorf("Run() = %v; want %v", err, errTransient)
+	}
+	if started {
+		t.Errorf("dependent worker was started")
+	}
+}
+
+// Test_Group_Shutdown_Order tests that workers are stopped one at a time, in
+// the reverse of the order in which they were started.
+func Test_Group_Shutdown_Order(t *testing.T) {
+	t.Parallel()
+	var lock sync.Mutex
+	var stopped []string
+	stoppable := func(name string) Worker {
+		return testFuncWorker(func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(5 * time.Millisecond)
+			lock.Lock()
+			defer lock.Unlock()
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+	g := NewGroup()
+	if err := g.Add("http", stoppable("http"), DependsOn("consumer")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("consumer", stoppable("consumer")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("metrics", stoppable("metrics")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+	if diff := cmp.Diff([]string{"metrics", "http", "consumer"}, stopped); diff != "" {
+		t.Errorf("stop order mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// stuckWorker returns a worker that ignores cancellation until the test
+// ends.
+func stuckWorker(t *testing.T) Worker {
+	release := make(chan struct{})
+	t.Cleanup(func() {
+		close(release)
+	})
+	return testFuncWorker(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+}
+
+// Test_Group_Shutdown_StopTimeout tests that a worker that does not stop
+// within its stop timeout is reported, and that the other workers are
+// stopped.
+func Test_Group_Shutdown_StopTimeout(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	stopped := false
+	if err := g.Add("db", testFuncWorker(func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped = true
+		return errTransient
+	})); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("stuck", stuckWorker(t), WithStopTimeout(10*time.Millisecond)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	cancel()
+	err := g.Wait()
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "worker stuck did not stop within 10ms") {
+		t.Errorf("Wait() = %v; want worker stuck did not stop within 10ms", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Errorf("Wait() = %v; want %v", err, errTransient)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("Wait() = %v; want 2 errors", err)
+	}
+	if !stopped {
+		t.Errorf("db worker was not stopped")
+	}
+}
+
+// Test_Group_Shutdown_Timeout tests that the workers that have not stopped
+// when the shutdown timeout expires are canceled and reported.
+func Test_Group_Shutdown_Timeout(t *testing.T) {
+	t.Parallel()
+	g := NewGroup(WithShutdownTimeout(20 * time.Millisecond))
+	queue, started, stopped := blockingWorker(nil)
+	if err := g.Add("queue", queue); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	for _, name := range []string{"stuck1", "stuck2"} {
+		if err := g.Add(name, stuckWorker(t)); err != nil {
+			t.Fatalf("Add() = %v; want nil", err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	<-started
+	cancel()
+	err := g.Wait()
+	// The queue worker is canceled when the timeout expires, and may not
+	// have stopped yet.
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "workers stuck2, stuck1") || !strings.HasSuffix(err.Error(), "did not stop within 20ms") {
+		t.Errorf("Wait() = %v; want workers stuck2, stuck1 did not stop within 20ms", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Errorf("queue worker was not canceled")
+	}
+}
+
+// blockingWorker returns a worker that runs until it is canceled, then
+// returns err. started is closed when it starts, and stopped when it stops.
+func blockingWorker(err error) (w Worker, started, stopped chan struct{}) {
+	started, stopped = make(chan struct{}), make(chan struct{})
+	return testFuncWorker(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return err
+	}), started, stopped
+}
+
+// Test_Group_Remove tests that Remove stops a worker without stopping the
+// other workers, and returns the error of the worker.
+func Test_Group_Remove(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	tenant1, started1, _ := blockingWorker(errTransient)
+	tenant2, _, stopped2 := blockingWorker(nil)
+	if err := g.Add("tenant1", tenant1); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("tenant2", tenant2); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	<-started1
+	if err := g.Remove("tenant1"); !errors.Is(err, errTransient) {
+		t.Errorf("Remove() = %v; want %v", err, errTransient)
+	}
+	if err := g.Remove("tenant1"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Remove() = %v; want %v", err, status.ErrNotFound)
+	}
+	if err := g.Remove("tenant2"); err != nil {
+		t.Errorf("Remove() = %v; want nil", err)
+	}
+	<-stopped2
+	// Removing the last worker before Wait does not stop the group.
+	tenant3, started3, _ := blockingWorker(nil)
+	if err := g.Add("tenant1", tenant3); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	<-started3
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+}
+
+// Test_Group_Remove_Last tests that removing the last worker while Wait is
+// waiting stops the group.
+func Test_Group_Remove_Last(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	tenant, started, _ := blockingWorker(nil)
+	if err := g.Add("tenant", tenant); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	<-started
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Wait()
+	}()
+	waitForWait(g)
+	if err := g.Remove("tenant"); err != nil {
+		t.Errorf("Remove() = %v; want nil", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait() = %v; want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait() did not return after the last worker was removed")
+	}
+}
+
+// waitForWait waits until Wait has been called on g.
+func waitForWait(g Group) {
+	gr := g.(*group)
+	for {
+		gr.lock.Lock()
+		waiting := gr.waiting
+		gr.lock.Unlock()
+		if waiting {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+// Test_Group_Remove_Errors tests the errors returned by Remove.
+func Test_Group_Remove_Errors(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	db, _, _ := blockingWorker(nil)
+	if err := g.Add("db", db); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	api, _, _ := blockingWorker(nil)
+	if err := g.Add("api", api, DependsOn("db")); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Add("stuck", stuckWorker(t), WithStopTimeout(10*time.Millisecond)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Remove("missing"); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Remove() = %v; want %v", err, status.ErrNotFound)
+	}
+	if err := g.Remove("db"); !errors.Is(err, status.ErrInvalidArgument) {
+		t.Errorf("Remove() = %v; want %v", err, status.ErrInvalidArgument)
+	}
+	// Before the group is started, workers are only removed.
+	if err := g.Remove("api"); err != nil {
+		t.Errorf("Remove() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	err := g.Remove("stuck")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Remove() = %v; want %v", err, context.DeadlineExceeded)
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+}
+
+// Test_Group_Replace tests that Replace stops a worker and starts another
+// with the same options, without stopping the other workers.
+func Test_Group_Replace(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	old, started, stopped := blockingWorker(nil)
+	if err := g.Add("feed", old, WithRestartPolicy(RestartAlways), WithBackoff(fastBackoff)); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	other, _, otherStopped := blockingWorker(nil)
+	if err := g.Add("other", other); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	if err := g.Replace("missing", old); !errors.Is(err, status.ErrNotFound) {
+		t.Errorf("Replace() = %v; want %v", err, status.ErrNotFound)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	<-started
+	var runs int32
+	if err := g.Replace("feed", testFuncWorker(func(ctx context.Context) error {
+		// The worker is restarted, as the options are kept.
+		if atomic.AddInt32(&runs, 1) == 3 {
+			cancel()
+		}
+		return nil
+	})); err != nil {
+		t.Errorf("Replace() = %v; want nil", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Errorf("replaced worker was not stopped")
+	}
+	select {
+	case <-otherStopped:
+		t.Errorf("other worker was stopped")
+	default:
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+	if n := atomic.LoadInt32(&runs); n < 3 {
+		t.Errorf("replacement runs = %v; want at least 3", n)
+	}
+	if err := g.Replace("feed", old); !errors.Is(err, context.Canceled) {
+		t.Errorf("Replace() = %v; want %v", err, context.Canceled)
+	}
+}
+
+// Test_Group_Add_AfterStart tests that a worker added to an empty group after
+// it is started runs, and is waited for.
+func Test_Group_Add_AfterStart(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	w, started, stopped := blockingWorker(nil)
+	if err := g.Add("tenant", w); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	<-started
+	waited := make(chan error, 1)
+	go func() {
+		waited <- g.Wait()
+	}()
+	select {
+	case err := <-waited:
+		t.Fatalf("Wait() = %v before the context was canceled", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	if err := <-waited; err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Errorf("worker was not stopped")
+	}
+}
+
+// Test_Group_Add_AfterWait tests that a worker cannot be added once the group
+// has stopped.
+func Test_Group_Add_AfterWait(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	if err := g.Run(context.Background(), newTestLogger(t)); err != nil {
+		t.Fatalf("Run() = %v; want nil", err)
+	}
+	ran := false
+	if err := g.Add("late", testFuncWorker(func(ctx context.Context) error {
+		ran = true
+		return nil
+	})); !errors.Is(err, context.Canceled) {
+		t.Errorf("Add() = %v; want %v", err, context.Canceled)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if ran {
+		t.Errorf("worker added after Wait was run")
+	}
+}
+
+// Test_Group_Replace_Backoff tests that a replacement does not share the
+// backoff strategy of the worker it replaces.
+func Test_Group_Replace_Backoff(t *testing.T) {
+	t.Parallel()
+	g := NewGroup()
+	failer := func() (Worker, chan struct{}) {
+		var runs int32
+		started := make(chan struct{})
+		return testFuncWorker(func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return errTransient
+			}
+			close(started)
+			<-ctx.Done()
+			return nil
+		}), started
+	}
+	old, oldStarted := failer()
+	if err := g.Add("feed", old, WithRestartPolicy(RestartOnFailure), WithBackoff(fixedBackoff(time.Millisecond, retry.WithMaxAttempts(1)))); err != nil {
+		t.Fatalf("Add() = %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := g.Start(ctx, newTestLogger(t)); err != nil {
+		t.Fatalf("Start() = %v; want nil", err)
+	}
+	<-oldStarted
+	replacement, started := failer()
+	if err := g.Replace("feed", replacement); err != nil {
+		t.Fatalf("Replace() = %v; want nil", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("replacement was not restarted")
+	}
+	cancel()
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; want nil", err)
+	}
+}